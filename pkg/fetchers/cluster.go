@@ -0,0 +1,117 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+// Platforms reported in the scan environment
+const (
+	PlatformKubernetes = "Kubernetes"
+	PlatformOpenShift  = "OpenShift"
+	PlatformEKS        = "EKS"
+	PlatformGKE        = "GKE"
+	PlatformK3s        = "k3s"
+	PlatformRKE2       = "RKE2"
+)
+
+// ClusterInfo reads the server version and detects the platform of the
+// cluster through discovery. The description is read once and reused by later
+// scans; failed reads are retried on the next call. Fetchers reading
+// pre-fetched files have no cluster to describe and return a zero ClusterInfo.
+func (k *KubernetesFetcher) ClusterInfo(ctx context.Context) (scanner.ClusterInfo, error) {
+	if k.discoveryClient == nil || k.apiResourcePath != "" {
+		return scanner.ClusterInfo{}, nil
+	}
+
+	k.clusterInfoMu.Lock()
+	defer k.clusterInfoMu.Unlock()
+	if k.clusterInfo != nil {
+		return *k.clusterInfo, nil
+	}
+	info, err := k.readClusterInfo(ctx)
+	if err != nil {
+		return info, err
+	}
+	k.clusterInfo = &info
+	return info, nil
+}
+
+// readClusterInfo makes the discovery calls describing the cluster
+func (k *KubernetesFetcher) readClusterInfo(ctx context.Context) (scanner.ClusterInfo, error) {
+
+	var serverVersion *version.Info
+	if _, err := k.callAPI(ctx, func(ctx context.Context) error {
+		var err error
+		serverVersion, err = k.discoveryClient.ServerVersion()
+		return err
+	}); err != nil {
+		return scanner.ClusterInfo{}, fmt.Errorf("failed to read server version: %w", err)
+	}
+
+	var groups *metav1.APIGroupList
+	if _, err := k.callAPI(ctx, func(ctx context.Context) error {
+		var err error
+		groups, err = k.discoveryClient.ServerGroups()
+		return err
+	}); err != nil {
+		return scanner.ClusterInfo{KubernetesVersion: serverVersion.GitVersion}, fmt.Errorf("failed to list API groups: %w", err)
+	}
+
+	return scanner.ClusterInfo{
+		KubernetesVersion: serverVersion.GitVersion,
+		Platform:          detectPlatform(serverVersion.GitVersion, groups),
+	}, nil
+}
+
+// ClusterInfo describes the cluster through the Kubernetes fetcher
+func (c *CompositeFetcher) ClusterInfo(ctx context.Context) (scanner.ClusterInfo, error) {
+	if c.kubernetesFetcher == nil {
+		return scanner.ClusterInfo{}, nil
+	}
+	return c.kubernetesFetcher.ClusterInfo(ctx)
+}
+
+// detectPlatform recognizes a distribution from API groups only it serves,
+// or from the suffix managed offerings add to the server version
+func detectPlatform(gitVersion string, groups *metav1.APIGroupList) string {
+	if groups != nil {
+		for _, group := range groups.Groups {
+			if group.Name == "config.openshift.io" {
+				return PlatformOpenShift
+			}
+		}
+	}
+
+	switch {
+	case strings.Contains(gitVersion, "-eks-"):
+		return PlatformEKS
+	case strings.Contains(gitVersion, "-gke."):
+		return PlatformGKE
+	case strings.Contains(gitVersion, "+k3s"):
+		return PlatformK3s
+	case strings.Contains(gitVersion, "+rke2"):
+		return PlatformRKE2
+	}
+	return PlatformKubernetes
+}
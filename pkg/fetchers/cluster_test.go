@@ -0,0 +1,145 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+)
+
+// versionDiscoveryClient serves a server version and API groups; only the
+// discovery calls used to describe the cluster are implemented
+type versionDiscoveryClient struct {
+	discovery.DiscoveryInterface
+	gitVersion string
+	groups     []string
+	failures   []error
+	calls      int // Discovery calls made, failed ones included
+}
+
+func (v *versionDiscoveryClient) ServerVersion() (*version.Info, error) {
+	v.calls++
+	if len(v.failures) > 0 {
+		err := v.failures[0]
+		v.failures = v.failures[1:]
+		return nil, err
+	}
+	return &version.Info{GitVersion: v.gitVersion}, nil
+}
+
+func (v *versionDiscoveryClient) ServerGroups() (*metav1.APIGroupList, error) {
+	v.calls++
+	list := &metav1.APIGroupList{}
+	for _, name := range v.groups {
+		list.Groups = append(list.Groups, metav1.APIGroup{Name: name})
+	}
+	return list, nil
+}
+
+func TestDetectPlatform(t *testing.T) {
+	tests := []struct {
+		name       string
+		gitVersion string
+		groups     []string
+		expected   string
+	}{
+		{name: "upstream", gitVersion: "v1.31.2", groups: []string{"apps", "batch"}, expected: PlatformKubernetes},
+		{name: "OpenShift", gitVersion: "v1.31.6", groups: []string{"apps", "config.openshift.io"}, expected: PlatformOpenShift},
+		{name: "EKS", gitVersion: "v1.30.8-eks-2d98532", expected: PlatformEKS},
+		{name: "GKE", gitVersion: "v1.30.5-gke.1014001", expected: PlatformGKE},
+		{name: "k3s", gitVersion: "v1.31.4+k3s1", expected: PlatformK3s},
+		{name: "RKE2", gitVersion: "v1.31.4+rke2r1", expected: PlatformRKE2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := &metav1.APIGroupList{}
+			for _, name := range tt.groups {
+				groups.Groups = append(groups.Groups, metav1.APIGroup{Name: name})
+			}
+			assert.Equal(t, tt.expected, detectPlatform(tt.gitVersion, groups))
+		})
+	}
+}
+
+func TestKubernetesFetcher_ClusterInfo(t *testing.T) {
+	t.Run("detects version and platform", func(t *testing.T) {
+		fetcher := NewKubernetesFetcher(nil, nil)
+		fetcher.discoveryClient = &versionDiscoveryClient{gitVersion: "v1.31.6", groups: []string{"config.openshift.io"}}
+
+		info, err := fetcher.ClusterInfo(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, scanner.ClusterInfo{KubernetesVersion: "v1.31.6", Platform: PlatformOpenShift}, info)
+	})
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		fetcher := NewKubernetesFetcher(nil, nil).WithRetryPolicy(RetryPolicy{MaxRetries: 1})
+		fetcher.discoveryClient = &versionDiscoveryClient{
+			gitVersion: "v1.31.2",
+			failures:   []error{apierrors.NewServiceUnavailable("restarting")},
+		}
+
+		info, err := fetcher.ClusterInfo(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "v1.31.2", info.KubernetesVersion)
+	})
+
+	t.Run("reads discovery once", func(t *testing.T) {
+		fetcher := NewKubernetesFetcher(nil, nil).WithRetryPolicy(NoRetryPolicy())
+		client := &versionDiscoveryClient{
+			gitVersion: "v1.31.2",
+			failures:   []error{apierrors.NewServiceUnavailable("restarting")},
+		}
+		fetcher.discoveryClient = client
+
+		_, err := fetcher.ClusterInfo(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, 1, client.calls)
+
+		for i := 0; i < 3; i++ {
+			info, err := fetcher.ClusterInfo(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "v1.31.2", info.KubernetesVersion)
+		}
+		assert.Equal(t, 3, client.calls, "a failed read is retried, a successful one is reused")
+	})
+
+	t.Run("pre-fetched resources describe no cluster", func(t *testing.T) {
+		info, err := NewKubernetesFileFetcher(t.TempDir()).ClusterInfo(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, scanner.ClusterInfo{}, info)
+	})
+
+	t.Run("composite fetcher", func(t *testing.T) {
+		composite := NewCompositeFetcher()
+		kubernetes := NewKubernetesFetcher(nil, nil)
+		kubernetes.discoveryClient = &versionDiscoveryClient{gitVersion: "v1.30.8-eks-2d98532"}
+		composite.SetKubernetesFetcher(kubernetes)
+
+		var provider scanner.ClusterInfoProvider = composite
+		info, err := provider.ClusterInfo(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, PlatformEKS, info.Platform)
+	})
+}
@@ -82,17 +82,21 @@ func (c *CompositeFetcher) FetchResources(ctx context.Context, rule scanner.Rule
 	// Use the new unified API directly
 	inputs := rule.Inputs()
 
-	data, err := c.FetchInputs(inputs, variables)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	return data, nil, nil
+	return c.FetchInputsWithContext(ctx, inputs, variables)
 }
 
 // FetchInputs retrieves inputs by delegating to appropriate specialized fetchers
 func (c *CompositeFetcher) FetchInputs(inputs []scanner.Input, variables []scanner.CelVariable) (map[string]interface{}, error) {
+	result, _, err := c.FetchInputsWithContext(context.Background(), inputs, variables)
+	return result, err
+}
+
+// FetchInputsWithContext retrieves inputs by delegating to appropriate specialized
+// fetchers. Fetchers that do not accept a context are adapted so that a cancelled
+// ctx still stops the fetch from being waited on.
+func (c *CompositeFetcher) FetchInputsWithContext(ctx context.Context, inputs []scanner.Input, variables []scanner.CelVariable) (map[string]interface{}, []string, error) {
 	result := make(map[string]interface{})
+	var warnings []string
 
 	// Group inputs by type
 	inputsByType := make(map[scanner.InputType][]scanner.Input)
@@ -104,12 +108,13 @@ func (c *CompositeFetcher) FetchInputs(inputs []scanner.Input, variables []scann
 	for inputType, typeInputs := range inputsByType {
 		fetcher := c.getFetcherForType(inputType)
 		if fetcher == nil {
-			return nil, fmt.Errorf("no fetcher available for input type: %s", inputType)
+			return nil, warnings, fmt.Errorf("no fetcher available for input type: %s", inputType)
 		}
 
-		data, err := fetcher.FetchInputs(typeInputs, variables)
+		data, typeWarnings, err := scanner.NewContextInputFetcher(fetcher).FetchInputsWithContext(ctx, typeInputs, variables)
+		warnings = append(warnings, typeWarnings...)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch inputs for type %s: %w", inputType, err)
+			return nil, warnings, fmt.Errorf("failed to fetch inputs for type %s: %w", inputType, err)
 		}
 
 		// Merge results
@@ -118,7 +123,7 @@ func (c *CompositeFetcher) FetchInputs(inputs []scanner.Input, variables []scann
 		}
 	}
 
-	return result, nil
+	return result, warnings, nil
 }
 
 // SupportsInputType returns true if any registered fetcher supports the input type
@@ -126,6 +131,15 @@ func (c *CompositeFetcher) SupportsInputType(inputType scanner.InputType) bool {
 	return c.getFetcherForType(inputType) != nil
 }
 
+// ResolveVariable reads variables referencing Kubernetes objects through the
+// Kubernetes fetcher
+func (c *CompositeFetcher) ResolveVariable(ctx context.Context, variable scanner.ObjectReferenceVariable) (string, error) {
+	if c.kubernetesFetcher == nil {
+		return "", fmt.Errorf("no Kubernetes fetcher configured")
+	}
+	return c.kubernetesFetcher.ResolveVariable(ctx, variable)
+}
+
 // getFetcherForType returns the appropriate fetcher for the input type
 func (c *CompositeFetcher) getFetcherForType(inputType scanner.InputType) scanner.InputFetcher {
 	// Check custom fetchers first
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
 	"github.com/stretchr/testify/assert"
@@ -177,6 +178,47 @@ func TestCompositeFetcher_FetchResources(t *testing.T) {
 		assert.Nil(t, warnings)
 		assert.Contains(t, err.Error(), "fetch failed")
 	})
+
+	t.Run("stops waiting when the context is cancelled", func(t *testing.T) {
+		fetcher := NewCompositeFetcher()
+		release := make(chan struct{})
+		defer close(release)
+		fetcher.RegisterCustomFetcher(scanner.InputTypeFile, &blockingInputFetcher{release: release})
+
+		rule := &mockRule{
+			ruleType:   scanner.RuleTypeCEL,
+			identifier: "test-rule",
+			inputs: []scanner.Input{
+				&mockInput{
+					name:      "test",
+					inputType: scanner.InputTypeFile,
+					spec:      &mockInputSpec{valid: true},
+				},
+			},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		result, _, err := fetcher.FetchResources(ctx, rule, nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Nil(t, result)
+	})
+}
+
+// blockingInputFetcher blocks every fetch until release is closed
+type blockingInputFetcher struct {
+	release chan struct{}
+}
+
+func (b *blockingInputFetcher) FetchInputs(inputs []scanner.Input, variables []scanner.CelVariable) (map[string]interface{}, error) {
+	<-b.release
+	return map[string]interface{}{}, nil
+}
+
+func (b *blockingInputFetcher) SupportsInputType(inputType scanner.InputType) bool {
+	return true
 }
 
 func TestCompositeFetcher_FetchInputs(t *testing.T) {
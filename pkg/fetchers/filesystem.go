@@ -16,6 +16,7 @@ limitations under the License.
 package fetchers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/fs"
@@ -45,7 +46,15 @@ func NewFilesystemFetcher(basePath string) *FilesystemFetcher {
 
 // FetchInputs retrieves file system resources for the specified inputs
 func (f *FilesystemFetcher) FetchInputs(inputs []scanner.Input, variables []scanner.CelVariable) (map[string]interface{}, error) {
+	result, _, err := f.FetchInputsWithContext(context.Background(), inputs, variables)
+	return result, err
+}
+
+// FetchInputsWithContext retrieves file system resources for the specified inputs,
+// stopping directory walks when ctx is cancelled or its deadline expires
+func (f *FilesystemFetcher) FetchInputsWithContext(ctx context.Context, inputs []scanner.Input, variables []scanner.CelVariable) (map[string]interface{}, []string, error) {
 	result := make(map[string]interface{})
+	var warnings []string
 
 	for _, input := range inputs {
 		if input.Type() != scanner.InputTypeFile {
@@ -54,18 +63,26 @@ func (f *FilesystemFetcher) FetchInputs(inputs []scanner.Input, variables []scan
 
 		fileSpec, ok := input.Spec().(scanner.FileInputSpec)
 		if !ok {
-			return nil, fmt.Errorf("invalid file input spec for input %s", input.Name())
+			return nil, warnings, fmt.Errorf("invalid file input spec for input %s", input.Name())
 		}
 
-		data, err := f.fetchFileResource(fileSpec)
+		data, inputWarnings, err := f.fetchFileResource(ctx, fileSpec)
+		for _, warning := range inputWarnings {
+			warnings = append(warnings, fmt.Sprintf("input %s: %s", input.Name(), warning))
+		}
+		if err != nil && scanner.IsOptionalInput(input) && scanner.IsMissingInputError(err) {
+			warnings = append(warnings, scanner.AbsentInputWarning(input, err))
+			result[input.Name()] = scanner.AbsentInputValue(input)
+			continue
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch file resource for input %s: %w", input.Name(), err)
+			return nil, warnings, fmt.Errorf("failed to fetch file resource for input %s: %w", input.Name(), err)
 		}
 
 		result[input.Name()] = data
 	}
 
-	return result, nil
+	return result, warnings, nil
 }
 
 // SupportsInputType returns true for file input types
@@ -74,7 +91,11 @@ func (f *FilesystemFetcher) SupportsInputType(inputType scanner.InputType) bool
 }
 
 // fetchFileResource retrieves a specific file system resource
-func (f *FilesystemFetcher) fetchFileResource(spec scanner.FileInputSpec) (interface{}, error) {
+func (f *FilesystemFetcher) fetchFileResource(ctx context.Context, spec scanner.FileInputSpec) (interface{}, []string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
 	path := spec.Path()
 
 	// Make path absolute if it's relative and we have a base path
@@ -85,33 +106,37 @@ func (f *FilesystemFetcher) fetchFileResource(spec scanner.FileInputSpec) (inter
 	// Check if path exists
 	info, err := os.Stat(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to stat path %s: %w", path, err)
+		return nil, nil, fmt.Errorf("failed to stat path %s: %w", path, err)
 	}
 
 	if info.IsDir() {
-		return f.fetchDirectory(path, spec)
+		return f.fetchDirectory(ctx, path, spec)
 	}
 
 	return f.fetchFile(path, spec)
 }
 
 // fetchFile reads and parses a single file
-func (f *FilesystemFetcher) fetchFile(path string, spec scanner.FileInputSpec) (interface{}, error) {
+func (f *FilesystemFetcher) fetchFile(path string, spec scanner.FileInputSpec) (interface{}, []string, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+		return nil, nil, fmt.Errorf("failed to read file %s: %w", path, err)
 	}
 
 	parsed, err := f.parseFileContent(data, spec.Format(), path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse file %s: %w", path, err)
+		return nil, nil, fmt.Errorf("failed to parse file %s: %w", path, err)
 	}
 
 	if !spec.CheckPermissions() {
-		return parsed, nil
+		return parsed, nil, nil
 	}
 
+	var warnings []string
 	mode, perm, owner, group, size := f.getFileMetadata(path)
+	if mode == "" {
+		warnings = append(warnings, fmt.Sprintf("could not read permissions of %s", path))
+	}
 
 	return map[string]interface{}{
 		"content": parsed,
@@ -120,7 +145,7 @@ func (f *FilesystemFetcher) fetchFile(path string, spec scanner.FileInputSpec) (
 		"owner":   owner,
 		"group":   group,
 		"size":    size,
-	}, nil
+	}, warnings, nil
 }
 
 // getFileMetadata retrieves file metadata including permissions, ownership, and group
@@ -155,8 +180,9 @@ func (f *FilesystemFetcher) getFileMetadata(path string) (mode, perm, owner, gro
 }
 
 // fetchDirectory reads files from a directory
-func (f *FilesystemFetcher) fetchDirectory(path string, spec scanner.FileInputSpec) (interface{}, error) {
+func (f *FilesystemFetcher) fetchDirectory(ctx context.Context, path string, spec scanner.FileInputSpec) (interface{}, []string, error) {
 	result := make(map[string]interface{})
+	var warnings []string
 
 	walkFunc := func(filePath string, info fs.FileInfo, err error) error {
 		if err != nil {
@@ -200,6 +226,9 @@ func (f *FilesystemFetcher) fetchDirectory(path string, spec scanner.FileInputSp
 		}
 
 		mode, perm, owner, group, size := f.getFileMetadata(filePath)
+		if mode == "" {
+			warnings = append(warnings, fmt.Sprintf("could not read permissions of %s", filePath))
+		}
 
 		result[relPath] = map[string]interface{}{
 			"content": parsed,
@@ -217,6 +246,11 @@ func (f *FilesystemFetcher) fetchDirectory(path string, spec scanner.FileInputSp
 			return err
 		}
 
+		// Stop walking as soon as the fetch is cancelled
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		info, err := d.Info()
 		if err != nil {
 			return err
@@ -226,10 +260,14 @@ func (f *FilesystemFetcher) fetchDirectory(path string, spec scanner.FileInputSp
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to walk directory %s: %w", path, err)
+		return nil, warnings, fmt.Errorf("failed to walk directory %s: %w", path, err)
 	}
 
-	return result, nil
+	if len(result) == 0 {
+		warnings = append(warnings, fmt.Sprintf("no files matching format %q found in %s", spec.Format(), path))
+	}
+
+	return result, warnings, nil
 }
 
 // parseFileContent parses file content based on format
@@ -16,6 +16,7 @@ limitations under the License.
 package fetchers
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -337,6 +338,44 @@ func TestFilesystemFetcher_FetchInputs_Directory(t *testing.T) {
 	})
 }
 
+func TestFilesystemFetcher_FetchInputsWithContext(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "filesystem_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	err = os.WriteFile(filepath.Join(tempDir, "file1.txt"), []byte("content1"), 0644)
+	require.NoError(t, err)
+
+	fetcher := NewFilesystemFetcher("")
+	input := scanner.NewFileInput("files", tempDir, "text", true, false)
+
+	t.Run("fetches with a live context", func(t *testing.T) {
+		result, warnings, err := fetcher.FetchInputsWithContext(context.Background(), []scanner.Input{input}, nil)
+		require.NoError(t, err)
+		assert.Nil(t, warnings)
+		assert.Contains(t, result, "files")
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result, _, err := fetcher.FetchInputsWithContext(ctx, []scanner.Input{input}, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, result)
+	})
+
+	t.Run("warns about a directory without matching files", func(t *testing.T) {
+		jsonInput := scanner.NewFileInput("configs", tempDir, "json", false, false)
+
+		result, warnings, err := fetcher.FetchInputsWithContext(context.Background(), []scanner.Input{jsonInput}, nil)
+		require.NoError(t, err)
+		assert.Empty(t, result["configs"])
+		require.Len(t, warnings, 1)
+		assert.Contains(t, warnings[0], "input configs: no files matching format \"json\"")
+	})
+}
+
 func TestFilesystemFetcher_FetchInputs_FormatFiltering(t *testing.T) {
 	// Create temporary test directory with different file types
 	tempDir, err := os.MkdirTemp("", "filesystem_test")
@@ -945,6 +984,38 @@ func TestFilesystemFetcher_Integration(t *testing.T) {
 }
 
 // Benchmark tests
+func TestFilesystemFetcher_PerObjectScan(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "filesystem_per_object_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	err = os.WriteFile(filepath.Join(tempDir, "private.key"), []byte("secret"), 0600)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "public.key"), []byte("secret"), 0644)
+	require.NoError(t, err)
+
+	rule, err := scanner.NewRuleBuilder("keys-private", scanner.RuleTypeCEL).
+		WithFileInput("keys", tempDir, "text", false, true).
+		SetCelExpression("object.perm == '0600'").
+		ForEachObject("keys").
+		BuildCelRule()
+	require.NoError(t, err)
+
+	composite := NewCompositeFetcher()
+	composite.RegisterCustomFetcher(scanner.InputTypeFile, NewFilesystemFetcher(""))
+	s := scanner.NewScanner(composite, nil)
+
+	results, err := s.Scan(context.Background(), scanner.ScanConfig{Rules: []scanner.Rule{rule}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	assert.Equal(t, scanner.CheckResultFail, results[0].Status)
+	assert.Equal(t, []scanner.ObjectFinding{
+		{Kind: "File", Name: "private.key", Status: scanner.CheckResultPass},
+		{Kind: "File", Name: "public.key", Status: scanner.CheckResultFail},
+	}, results[0].Findings)
+}
+
 func BenchmarkFilesystemFetcher_FetchTextFile(b *testing.B) {
 	tempDir, err := os.MkdirTemp("", "filesystem_bench")
 	require.NoError(b, err)
@@ -1004,3 +1075,22 @@ func BenchmarkFilesystemFetcher_FetchDirectory(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestFilesystemFetcher_OptionalInputs(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "present.txt"), []byte("here"), 0644))
+
+	fetcher := NewFilesystemFetcher(tempDir)
+	inputs := []scanner.Input{
+		scanner.NewOptionalInput(scanner.NewFileInput("absent", "absent.txt", "text", false, false)),
+		scanner.NewOptionalInput(scanner.NewFileInput("absentDir", "conf.d", "", true, false)),
+		scanner.NewFileInput("present", "present.txt", "text", false, false),
+	}
+
+	result, warnings, err := fetcher.FetchInputsWithContext(context.Background(), inputs, nil)
+	require.NoError(t, err)
+	assert.Nil(t, result["absent"])
+	assert.Equal(t, map[string]interface{}{}, result["absentDir"])
+	assert.Equal(t, "here", result["present"])
+	assert.Len(t, warnings, 2)
+}
@@ -25,11 +25,14 @@ import (
 	"sync"
 
 	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/flowcontrol"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -70,6 +73,11 @@ type KubernetesFetcher struct {
 	discoveryClient discovery.DiscoveryInterface
 	apiResourcePath string // Path to pre-fetched API resources (optional)
 	config          *ResourceMappingConfig
+	retryPolicy     RetryPolicy
+	rateLimiter     flowcontrol.RateLimiter // Shared by all API calls of the fetcher (optional)
+
+	clusterInfoMu sync.Mutex
+	clusterInfo   *scanner.ClusterInfo // Read once for the lifetime of the fetcher
 }
 
 // NewKubernetesFetcher creates a new Kubernetes input fetcher
@@ -84,6 +92,7 @@ func NewKubernetesFetcher(client runtimeclient.Client, clientset kubernetes.Inte
 		clientset:       clientset,
 		discoveryClient: discoveryClient,
 		config:          DefaultResourceMappingConfig(),
+		retryPolicy:     DefaultRetryPolicy(),
 	}
 }
 
@@ -103,7 +112,15 @@ func (k *KubernetesFetcher) WithConfig(config *ResourceMappingConfig) *Kubernete
 
 // FetchInputs retrieves Kubernetes resources for the specified inputs
 func (k *KubernetesFetcher) FetchInputs(inputs []scanner.Input, variables []scanner.CelVariable) (map[string]interface{}, error) {
+	result, _, err := k.FetchInputsWithContext(context.Background(), inputs, variables)
+	return result, err
+}
+
+// FetchInputsWithContext retrieves Kubernetes resources for the specified inputs,
+// aborting API calls when ctx is cancelled or its deadline expires
+func (k *KubernetesFetcher) FetchInputsWithContext(ctx context.Context, inputs []scanner.Input, variables []scanner.CelVariable) (map[string]interface{}, []string, error) {
 	result := make(map[string]interface{})
+	var warnings []string
 
 	for _, input := range inputs {
 		if input.Type() != scanner.InputTypeKubernetes {
@@ -112,18 +129,49 @@ func (k *KubernetesFetcher) FetchInputs(inputs []scanner.Input, variables []scan
 
 		kubeSpec, ok := input.Spec().(scanner.KubernetesInputSpec)
 		if !ok {
-			return nil, fmt.Errorf("invalid Kubernetes input spec for input %s", input.Name())
+			return nil, warnings, fmt.Errorf("invalid Kubernetes input spec for input %s", input.Name())
 		}
 
-		data, err := k.fetchKubernetesResource(kubeSpec)
+		data, retries, err := k.fetchKubernetesResource(ctx, kubeSpec)
+		if retries > 0 && err == nil {
+			warnings = append(warnings, fmt.Sprintf("input %s: fetched after %d retries", input.Name(), retries))
+		}
+		if err != nil && scanner.IsOptionalInput(input) && scanner.IsMissingInputError(err) {
+			warnings = append(warnings, scanner.AbsentInputWarning(input, err))
+			result[input.Name()] = scanner.AbsentInputValue(input)
+			continue
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch Kubernetes resource for input %s: %w", input.Name(), err)
+			return nil, warnings, fmt.Errorf("failed to fetch Kubernetes resource for input %s: %w", input.Name(), err)
 		}
 
+		warnings = append(warnings, k.resourceWarnings(input.Name(), kubeSpec, data)...)
 		result[input.Name()] = data
 	}
 
-	return result, nil
+	return result, warnings, nil
+}
+
+// resourceWarnings reports fetched resources a rule author may not expect:
+// namespaces ignored for cluster-scoped resources and empty lists
+func (k *KubernetesFetcher) resourceWarnings(inputName string, spec scanner.KubernetesInputSpec, data interface{}) []string {
+	var warnings []string
+
+	if spec.Namespace() != "" && !IsNamespacedWithConfig(spec, k.discoveryClient, k.config) {
+		warnings = append(warnings, fmt.Sprintf("input %s: namespace %s ignored for cluster-scoped resource %s", inputName, spec.Namespace(), spec.ResourceType()))
+	}
+
+	if list, ok := data.(map[string]interface{}); ok && spec.Name() == "" {
+		if items, ok := list["items"].([]interface{}); ok && len(items) == 0 {
+			location := ""
+			if spec.Namespace() != "" {
+				location = " in namespace " + spec.Namespace()
+			}
+			warnings = append(warnings, fmt.Sprintf("input %s: no %s found%s", inputName, spec.ResourceType(), location))
+		}
+	}
+
+	return warnings
 }
 
 // SupportsInputType returns true for Kubernetes input types
@@ -132,18 +180,23 @@ func (k *KubernetesFetcher) SupportsInputType(inputType scanner.InputType) bool
 }
 
 // fetchKubernetesResource retrieves a specific Kubernetes resource
-func (k *KubernetesFetcher) fetchKubernetesResource(spec scanner.KubernetesInputSpec) (interface{}, error) {
+func (k *KubernetesFetcher) fetchKubernetesResource(ctx context.Context, spec scanner.KubernetesInputSpec) (interface{}, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
 	if k.apiResourcePath != "" {
 		// Fetch from pre-cached files
-		return k.fetchFromFile(spec)
+		data, err := k.fetchFromFile(spec)
+		return data, 0, err
 	}
 
 	if k.client == nil {
-		return nil, fmt.Errorf("no Kubernetes client available")
+		return nil, 0, fmt.Errorf("no Kubernetes client available")
 	}
 
 	// Fetch from live API
-	return k.fetchFromAPI(spec)
+	return k.fetchFromAPI(ctx, spec)
 }
 
 // fetchFromFile reads resources from pre-cached files
@@ -169,16 +222,16 @@ func (k *KubernetesFetcher) fetchFromFile(spec scanner.KubernetesInputSpec) (int
 
 	// Filter by name if specified
 	if spec.Name() != "" {
-		return filterResourceByName(data, spec.Name())
+		gr := schema.GroupResource{Group: spec.ApiGroup(), Resource: spec.ResourceType()}
+		return filterResourceByName(data, gr, spec.Name())
 	}
 
 	return data, nil
 }
 
-// fetchFromAPI retrieves resources from the Kubernetes API
-func (k *KubernetesFetcher) fetchFromAPI(spec scanner.KubernetesInputSpec) (interface{}, error) {
-	ctx := context.Background()
-
+// fetchFromAPI retrieves resources from the Kubernetes API, returning the
+// number of retries the calls needed
+func (k *KubernetesFetcher) fetchFromAPI(ctx context.Context, spec scanner.KubernetesInputSpec) (interface{}, int, error) {
 	// Create GVK using dynamic discovery
 	gvk := GetGVKWithConfig(spec, k.config, k.discoveryClient)
 
@@ -200,11 +253,14 @@ func (k *KubernetesFetcher) fetchFromAPI(spec scanner.KubernetesInputSpec) (inte
 			key.Namespace = spec.Namespace()
 		}
 
-		if err := k.client.Get(ctx, key, obj); err != nil {
-			return nil, fmt.Errorf("failed to get resource %s/%s: %w", spec.ResourceType(), spec.Name(), err)
+		retries, err := k.callAPI(ctx, func(ctx context.Context) error {
+			return k.client.Get(ctx, key, obj)
+		})
+		if err != nil {
+			return nil, retries, fmt.Errorf("failed to get resource %s/%s: %w", spec.ResourceType(), spec.Name(), err)
 		}
 
-		return obj.Object, nil
+		return obj.Object, retries, nil
 	}
 
 	// Fetch list of resources
@@ -218,8 +274,11 @@ func (k *KubernetesFetcher) fetchFromAPI(spec scanner.KubernetesInputSpec) (inte
 		listOpts.Namespace = spec.Namespace()
 	}
 
-	if err := k.client.List(ctx, list, listOpts); err != nil {
-		return nil, fmt.Errorf("failed to list resources %s: %w", spec.ResourceType(), err)
+	retries, err := k.callAPI(ctx, func(ctx context.Context) error {
+		return k.client.List(ctx, list, listOpts)
+	})
+	if err != nil {
+		return nil, retries, fmt.Errorf("failed to list resources %s: %w", spec.ResourceType(), err)
 	}
 
 	// Convert to the expected format
@@ -233,11 +292,13 @@ func (k *KubernetesFetcher) fetchFromAPI(spec scanner.KubernetesInputSpec) (inte
 		result["items"].([]interface{})[i] = item.Object
 	}
 
-	return result, nil
+	return result, retries, nil
 }
 
 // Helper functions
 
+// readJSONFile reads a pre-fetched resource file, decoding whole numbers as
+// int64 like objects read from the API
 func readJSONFile(filePath string) (map[string]interface{}, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -245,14 +306,14 @@ func readJSONFile(filePath string) (map[string]interface{}, error) {
 	}
 
 	var result map[string]interface{}
-	if err := json.Unmarshal(data, &result); err != nil {
+	if err := utiljson.Unmarshal(data, &result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal JSON from file %s: %w", filePath, err)
 	}
 
 	return result, nil
 }
 
-func filterResourceByName(data map[string]interface{}, name string) (interface{}, error) {
+func filterResourceByName(data map[string]interface{}, gr schema.GroupResource, name string) (interface{}, error) {
 	// Extract single resource from list by name
 	items, ok := data["items"].([]interface{})
 	if !ok {
@@ -269,7 +330,7 @@ func filterResourceByName(data map[string]interface{}, name string) (interface{}
 		}
 	}
 
-	return nil, fmt.Errorf("resource %s not found", name)
+	return nil, apierrors.NewNotFound(gr, name)
 }
 
 // KubernetesInputSpec implementation helpers
@@ -16,10 +16,19 @@ limitations under the License.
 package fetchers
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
 )
 
 // mockKubernetesInputSpec implements KubernetesInputSpec for testing
@@ -38,6 +47,43 @@ func (m *mockKubernetesInputSpec) Namespace() string    { return m.namespace }
 func (m *mockKubernetesInputSpec) Name() string         { return m.name }
 func (m *mockKubernetesInputSpec) Validate() error      { return nil }
 
+// mockDiscoveryClient serves API resources from a static map; only the
+// discovery calls used by the fetchers are implemented
+type mockDiscoveryClient struct {
+	discovery.DiscoveryInterface
+
+	mu        sync.Mutex
+	resources map[string][]metav1.APIResource
+	calls     int
+}
+
+func (m *mockDiscoveryClient) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+
+	resources, ok := m.resources[groupVersion]
+	if !ok {
+		return nil, fmt.Errorf("group version %s not found", groupVersion)
+	}
+	return &metav1.APIResourceList{GroupVersion: groupVersion, APIResources: resources}, nil
+}
+
+func newMockDiscoveryClient() *mockDiscoveryClient {
+	return &mockDiscoveryClient{
+		resources: map[string][]metav1.APIResource{
+			"v1": {
+				{Name: "pods", SingularName: "pod", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"get", "list", "watch"}},
+				{Name: "nodes", SingularName: "node", Kind: "Node", Namespaced: false, Verbs: metav1.Verbs{"get", "list", "watch"}},
+				{Name: "configmaps", SingularName: "configmap", Kind: "ConfigMap", Namespaced: true, Verbs: metav1.Verbs{"get", "list", "watch"}},
+			},
+			"apps/v1": {
+				{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true, Verbs: metav1.Verbs{"get", "list", "watch"}},
+			},
+		},
+	}
+}
+
 func TestIsNamespacedWithDynamicDiscovery(t *testing.T) {
 	// Clear cache before testing
 	ClearDiscoveryCache()
@@ -270,6 +316,47 @@ func TestValidateKubernetesInputSpec(t *testing.T) {
 	}
 }
 
+func TestResourceDiscoveryCache_ConcurrentAccess(t *testing.T) {
+	ClearDiscoveryCache()
+	defer ClearDiscoveryCache()
+
+	discoveryClient := newMockDiscoveryClient()
+	specs := []struct {
+		spec       scanner.KubernetesInputSpec
+		kind       string
+		namespaced bool
+	}{
+		{&mockKubernetesInputSpec{version: "v1", resourceType: "pods"}, "Pod", true},
+		{&mockKubernetesInputSpec{version: "v1", resourceType: "nodes"}, "Node", false},
+		{&mockKubernetesInputSpec{apiGroup: "apps", version: "v1", resourceType: "deployments"}, "Deployment", true},
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 64*len(specs))
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tc := specs[i%len(specs)]
+			if kind := GetGVKWithConfig(tc.spec, DefaultResourceMappingConfig(), discoveryClient).Kind; kind != tc.kind {
+				errs <- fmt.Sprintf("expected kind %s, got %s", tc.kind, kind)
+			}
+			if namespaced := IsNamespacedWithConfig(tc.spec, discoveryClient, DefaultResourceMappingConfig()); namespaced != tc.namespaced {
+				errs <- fmt.Sprintf("expected %s namespaced=%v, got %v", tc.kind, tc.namespaced, namespaced)
+			}
+			if i%16 == 0 {
+				ClearDiscoveryCache()
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
 func TestResourceDiscoveryCache(t *testing.T) {
 	// Clear the cache before testing
 	ClearDiscoveryCache()
@@ -377,3 +464,45 @@ func TestWithConfig(t *testing.T) {
 		t.Error("Expected fetcher to use the provided config")
 	}
 }
+
+func TestKubernetesFetcher_FetchInputsWarnings(t *testing.T) {
+	apiResourcePath := t.TempDir()
+	dir := filepath.Join(apiResourcePath, "namespaces", "empty")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pods.json"), []byte(`{"items": []}`), 0644))
+
+	fetcher := NewKubernetesFileFetcher(apiResourcePath)
+	input := scanner.NewKubernetesInput("pods", "", "v1", "pods", "empty", "")
+
+	result, warnings, err := fetcher.FetchInputsWithContext(context.Background(), []scanner.Input{input}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, result, "pods")
+	assert.Equal(t, []string{"input pods: no pods found in namespace empty"}, warnings)
+}
+
+func TestKubernetesFetcher_OptionalInputs(t *testing.T) {
+	apiResourcePath := t.TempDir()
+	dir := filepath.Join(apiResourcePath, "namespaces", "default")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "configmaps.json"), []byte(`{"items": []}`), 0644))
+
+	fetcher := NewKubernetesFileFetcher(apiResourcePath)
+	inputs := []scanner.Input{
+		scanner.NewOptionalInput(scanner.NewKubernetesInput("policies", "policy.example.com", "v1", "policies", "default", "")),
+		scanner.NewOptionalInput(scanner.NewKubernetesInput("tuning", "", "v1", "configmaps", "default", "tuning")),
+		scanner.NewKubernetesInput("configmaps", "", "v1", "configmaps", "default", ""),
+	}
+
+	result, warnings, err := fetcher.FetchInputsWithContext(context.Background(), inputs, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"items": []interface{}{}}, result["policies"])
+	assert.Contains(t, result, "tuning")
+	assert.Nil(t, result["tuning"])
+	assert.Contains(t, result, "configmaps")
+	assert.Len(t, warnings, 3) // two optional inputs not found, one empty list
+
+	_, _, err = fetcher.FetchInputsWithContext(context.Background(), []scanner.Input{
+		scanner.NewKubernetesInput("tuning", "", "v1", "configmaps", "default", "tuning"),
+	}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,370 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	"k8s.io/client-go/discovery"
+)
+
+// Verbs of planned Kubernetes accesses
+const (
+	VerbGet  = "get"
+	VerbList = "list"
+)
+
+// ScanPlan lists everything a scan would access, deduplicated, with the
+// rules depending on each access
+type ScanPlan struct {
+	Resources []PlannedResource `json:"resources,omitempty"`
+	Files     []PlannedFile     `json:"files,omitempty"`
+	Commands  []PlannedCommand  `json:"commands,omitempty"`
+	Requests  []PlannedRequest  `json:"requests,omitempty"`
+
+	// UnevaluatedRules lists the skipped and manual rules, which access nothing
+	UnevaluatedRules []string `json:"unevaluatedRules,omitempty"`
+}
+
+// PlannedResource is a Kubernetes resource a scan would read
+type PlannedResource struct {
+	Group       string   `json:"group,omitempty"`
+	Version     string   `json:"version"`
+	Resource    string   `json:"resource"`
+	Subresource string   `json:"subresource,omitempty"` // Set for resource types such as "pods/log"
+	Kind        string   `json:"kind"`
+	Namespaced  bool     `json:"namespaced"`
+	Namespace   string   `json:"namespace,omitempty"` // Empty for cluster-scoped resources and lists across namespaces
+	Name        string   `json:"name,omitempty"`      // Empty when the resource is listed
+	Verb        string   `json:"verb"`                // VerbGet or VerbList
+	Rules       []string `json:"rules,omitempty"`     // Rules reading the resource through an input
+	Variables   []string `json:"variables,omitempty"` // Variables read from a field of the object
+}
+
+// GroupVersionResource returns the resource formatted as group/version/resource,
+// or version/resource for the core group, followed by its subresource
+func (r PlannedResource) GroupVersionResource() string {
+	resource := r.Version + "/" + r.Resource
+	if r.Group != "" {
+		resource = r.Group + "/" + resource
+	}
+	if r.Subresource != "" {
+		resource += "/" + r.Subresource
+	}
+	return resource
+}
+
+// PlannedFile is a file or directory a scan would read
+type PlannedFile struct {
+	Path             string   `json:"path"`
+	Recursive        bool     `json:"recursive,omitempty"`        // The directory is walked recursively
+	CheckPermissions bool     `json:"checkPermissions,omitempty"` // Ownership and permissions are read
+	Rules            []string `json:"rules"`
+}
+
+// PlannedCommand is a system service or command a scan would query
+type PlannedCommand struct {
+	Service string   `json:"service,omitempty"`
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Rules   []string `json:"rules"`
+}
+
+// PlannedRequest is an HTTP request a scan would send
+type PlannedRequest struct {
+	Method string   `json:"method"`
+	URL    string   `json:"url"`
+	Rules  []string `json:"rules"`
+}
+
+// scanPlanner resolves inputs the way the fetchers would
+type scanPlanner struct {
+	mappings        *ResourceMappingConfig
+	discoveryClient discovery.DiscoveryInterface
+	basePath        string
+
+	plan      ScanPlan
+	resources map[string]int // Indexes in plan.Resources by access key
+	files     map[string]int
+	commands  map[string]int
+	requests  map[string]int
+}
+
+// PlanScan lists what a scan of config would access without fetching
+// anything. Kinds and scopes of resources are resolved by GetGVKWithConfig
+// and IsNamespacedWithConfig with mappings and, when discoveryClient is not
+// nil, API discovery. Rules skipped by the configuration and manual rules
+// access nothing.
+func PlanScan(config scanner.ScanConfig, mappings *ResourceMappingConfig, discoveryClient discovery.DiscoveryInterface) ScanPlan {
+	planner := &scanPlanner{mappings: mappings, discoveryClient: discoveryClient}
+	return planner.build(config)
+}
+
+// Plan lists what a scan of config through the composite fetcher would
+// access, resolving resources with the configuration and discovery client of
+// its Kubernetes fetcher and relative file paths against the base path of its
+// filesystem fetcher
+func (c *CompositeFetcher) Plan(config scanner.ScanConfig) ScanPlan {
+	planner := &scanPlanner{mappings: DefaultResourceMappingConfig()}
+	if c.kubernetesFetcher != nil {
+		planner.mappings = c.kubernetesFetcher.config
+		planner.discoveryClient = c.kubernetesFetcher.discoveryClient
+	}
+	if c.filesystemFetcher != nil {
+		planner.basePath = c.filesystemFetcher.basePath
+	}
+	return planner.build(config)
+}
+
+// build plans every evaluated rule and object-referencing variable of config
+func (p *scanPlanner) build(config scanner.ScanConfig) ScanPlan {
+	p.resources = make(map[string]int)
+	p.files = make(map[string]int)
+	p.commands = make(map[string]int)
+	p.requests = make(map[string]int)
+
+	skipped := make(map[string]bool, len(config.SkipRules))
+	for _, id := range config.SkipRules {
+		skipped[id] = true
+	}
+
+	for _, rule := range config.Rules {
+		if skipped[rule.Identifier()] || isManualRule(rule) {
+			p.plan.UnevaluatedRules = append(p.plan.UnevaluatedRules, rule.Identifier())
+			continue
+		}
+		for _, input := range rule.Inputs() {
+			p.addInput(rule.Identifier(), input)
+		}
+	}
+
+	for _, variable := range config.Variables {
+		if ref, ok := variable.(scanner.ObjectReferenceVariable); ok && ref.ObjectName() != "" {
+			p.addVariable(ref)
+		}
+	}
+
+	sort.SliceStable(p.plan.Resources, func(i, j int) bool {
+		a, b := p.plan.Resources[i], p.plan.Resources[j]
+		if a.GroupVersionResource() != b.GroupVersionResource() {
+			return a.GroupVersionResource() < b.GroupVersionResource()
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	sort.SliceStable(p.plan.Files, func(i, j int) bool { return p.plan.Files[i].Path < p.plan.Files[j].Path })
+	return p.plan
+}
+
+// isManualRule reports whether a rule is checked manually and never evaluated
+func isManualRule(rule scanner.Rule) bool {
+	kindRule, ok := rule.(scanner.CheckKindRule)
+	return ok && kindRule.CheckKind() == scanner.CheckKindManual
+}
+
+// addInput records the access an input makes on behalf of a rule
+func (p *scanPlanner) addInput(ruleID string, input scanner.Input) {
+	switch spec := input.Spec().(type) {
+	case scanner.KubernetesInputSpec:
+		resource := p.resolveResource(spec)
+		index := p.resourceIndex(resource)
+		p.plan.Resources[index].Rules = appendUnique(p.plan.Resources[index].Rules, ruleID)
+
+	case scanner.FileInputSpec:
+		path := spec.Path()
+		if p.basePath != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(p.basePath, path)
+		}
+		index, ok := p.files[path]
+		if !ok {
+			index = len(p.plan.Files)
+			p.files[path] = index
+			p.plan.Files = append(p.plan.Files, PlannedFile{Path: path})
+		}
+		file := &p.plan.Files[index]
+		file.Recursive = file.Recursive || spec.Recursive()
+		file.CheckPermissions = file.CheckPermissions || spec.CheckPermissions()
+		file.Rules = appendUnique(file.Rules, ruleID)
+
+	case scanner.SystemInputSpec:
+		key := strings.Join(append([]string{spec.ServiceName(), spec.Command()}, spec.Args()...), "\x00")
+		index, ok := p.commands[key]
+		if !ok {
+			index = len(p.plan.Commands)
+			p.commands[key] = index
+			p.plan.Commands = append(p.plan.Commands, PlannedCommand{Service: spec.ServiceName(), Command: spec.Command(), Args: spec.Args()})
+		}
+		p.plan.Commands[index].Rules = appendUnique(p.plan.Commands[index].Rules, ruleID)
+
+	case scanner.HTTPInputSpec:
+		method := spec.Method()
+		if method == "" {
+			method = "GET"
+		}
+		key := method + " " + spec.URL()
+		index, ok := p.requests[key]
+		if !ok {
+			index = len(p.plan.Requests)
+			p.requests[key] = index
+			p.plan.Requests = append(p.plan.Requests, PlannedRequest{Method: method, URL: spec.URL()})
+		}
+		p.plan.Requests[index].Rules = appendUnique(p.plan.Requests[index].Rules, ruleID)
+	}
+}
+
+// addVariable records the object a variable is read from
+func (p *scanPlanner) addVariable(variable scanner.ObjectReferenceVariable) {
+	gvk := variable.GroupVersionKind()
+	resource := p.resolveResource(variableInput(variable))
+	if gvk.Kind != "" {
+		resource.Kind = gvk.Kind
+	}
+	index := p.resourceIndex(resource)
+	p.plan.Resources[index].Variables = appendUnique(p.plan.Resources[index].Variables, variable.Name())
+}
+
+// resolveResource describes the access a Kubernetes input specification
+// makes. Namespaces are dropped for cluster-scoped resources, as the fetcher
+// does; subresources take the kind and scope of their resource.
+func (p *scanPlanner) resolveResource(spec scanner.KubernetesInputSpec) PlannedResource {
+	resourceType, subresource, _ := strings.Cut(spec.ResourceType(), "/")
+	parent := &scanner.KubernetesInput{
+		Group:   spec.ApiGroup(),
+		Ver:     spec.Version(),
+		ResType: resourceType,
+		Ns:      spec.Namespace(),
+		ResName: spec.Name(),
+	}
+
+	namespaced := IsNamespacedWithConfig(parent, p.discoveryClient, p.mappings)
+	resource := PlannedResource{
+		Group:       spec.ApiGroup(),
+		Version:     spec.Version(),
+		Resource:    resourceType,
+		Subresource: subresource,
+		Kind:        GetGVKWithConfig(parent, p.mappings, p.discoveryClient).Kind,
+		Namespaced:  namespaced,
+		Name:        spec.Name(),
+		Verb:        VerbList,
+	}
+	if namespaced {
+		resource.Namespace = spec.Namespace()
+	}
+	if spec.Name() != "" {
+		resource.Verb = VerbGet
+	}
+	return resource
+}
+
+// resourceIndex returns the index of the planned resource, adding it if needed
+func (p *scanPlanner) resourceIndex(resource PlannedResource) int {
+	key := strings.Join([]string{resource.GroupVersionResource(), resource.Namespace, resource.Name}, "|")
+	index, ok := p.resources[key]
+	if !ok {
+		index = len(p.plan.Resources)
+		p.resources[key] = index
+		p.plan.Resources = append(p.plan.Resources, resource)
+	}
+	return index
+}
+
+// appendUnique appends value unless values already holds it
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// WriteText writes the plan as tables, one per kind of access
+func (p ScanPlan) WriteText(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	if len(p.Resources) > 0 {
+		fmt.Fprintln(table, "RESOURCE\tKIND\tNAMESPACE\tNAME\tVERB\tRULES")
+		for _, resource := range p.Resources {
+			namespace := resource.Namespace
+			switch {
+			case !resource.Namespaced:
+				namespace = "(cluster)"
+			case namespace == "":
+				namespace = "(all)"
+			}
+			dependents := append(append([]string(nil), resource.Rules...), prefixed("var:", resource.Variables)...)
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n", resource.GroupVersionResource(), resource.Kind, namespace,
+				orDash(resource.Name), resource.Verb, strings.Join(dependents, ","))
+		}
+		fmt.Fprintln(table)
+	}
+
+	if len(p.Files) > 0 {
+		fmt.Fprintln(table, "PATH\tRECURSIVE\tPERMISSIONS\tRULES")
+		for _, file := range p.Files {
+			fmt.Fprintf(table, "%s\t%t\t%t\t%s\n", file.Path, file.Recursive, file.CheckPermissions, strings.Join(file.Rules, ","))
+		}
+		fmt.Fprintln(table)
+	}
+
+	if len(p.Commands) > 0 {
+		fmt.Fprintln(table, "SERVICE\tCOMMAND\tRULES")
+		for _, command := range p.Commands {
+			fmt.Fprintf(table, "%s\t%s\t%s\n", orDash(command.Service),
+				orDash(strings.TrimSpace(command.Command+" "+strings.Join(command.Args, " "))), strings.Join(command.Rules, ","))
+		}
+		fmt.Fprintln(table)
+	}
+
+	if len(p.Requests) > 0 {
+		fmt.Fprintln(table, "METHOD\tURL\tRULES")
+		for _, request := range p.Requests {
+			fmt.Fprintf(table, "%s\t%s\t%s\n", request.Method, request.URL, strings.Join(request.Rules, ","))
+		}
+		fmt.Fprintln(table)
+	}
+
+	if len(p.UnevaluatedRules) > 0 {
+		fmt.Fprintf(table, "Not evaluated: %s\n", strings.Join(p.UnevaluatedRules, ","))
+	}
+
+	return table.Flush()
+}
+
+// prefixed returns values with prefix prepended
+func prefixed(prefix string, values []string) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = prefix + value
+	}
+	return result
+}
+
+// orDash returns value, or "-" when it is empty
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// planConfig scans pods, nodes, files, a command and an HTTP endpoint
+func planConfig(t *testing.T) scanner.ScanConfig {
+	build := func(builder *scanner.RuleBuilder) scanner.Rule {
+		rule, err := builder.Build()
+		require.NoError(t, err)
+		return rule
+	}
+	return scanner.ScanConfig{
+		Rules: []scanner.Rule{
+			build(scanner.NewRuleBuilder("pods-limits", scanner.RuleTypeCEL).
+				WithKubernetesInput("pods", "", "v1", "pods", "apps", "").
+				SetCelExpression("pods.items.size() >= 0")),
+			build(scanner.NewRuleBuilder("pods-labels", scanner.RuleTypeCEL).
+				WithKubernetesInput("workloads", "", "v1", "pods", "apps", "").
+				WithKubernetesInput("nodes", "", "v1", "nodes", "apps", "").
+				SetCelExpression("workloads.items.size() >= nodes.items.size()")),
+			build(scanner.NewRuleBuilder("kubelet-config", scanner.RuleTypeCEL).
+				WithFileInput("kubelet", "etc/kubernetes/kubelet.conf", "yaml", false, false).
+				WithFileInput("manifests", "/etc/kubernetes/manifests", "yaml", true, true).
+				SetCelExpression("size(manifests) > 0")),
+			build(scanner.NewRuleBuilder("sshd", scanner.RuleTypeCEL).
+				WithSystemInput("sshd", "sshd", "", nil).
+				WithHTTPInput("health", "https://localhost:10250/healthz", "", nil, nil).
+				SetCelExpression("sshd != null")),
+			build(scanner.NewRuleBuilder("deselected", scanner.RuleTypeCEL).
+				WithKubernetesInput("secrets", "", "v1", "secrets", "", "").
+				SetCelExpression("secrets.items.size() == 0")),
+			build(scanner.NewRuleBuilder("review", scanner.RuleTypeCEL).
+				WithKubernetesInput("roles", "rbac.authorization.k8s.io", "v1", "roles", "", "").
+				WithCheckKind(scanner.CheckKindManual).
+				SetCelExpression("true")),
+		},
+		SkipRules: []string{"deselected"},
+		Variables: []scanner.CelVariable{
+			scanner.NewObjectCelVariable("maxPods", configMapGVK, "compliance", "tailoring", "data.maxPods", scanner.VariableTypeInt),
+		},
+	}
+}
+
+func TestPlanScan(t *testing.T) {
+	mappings := DefaultResourceMappingConfig()
+	mappings.CustomKindMappings["nodes"] = "Node"
+	mappings.CustomKindMappings["pods"] = "Pod"
+	mappings.CustomScopeMappings[schema.GroupVersionKind{Version: "v1", Kind: "Node"}] = false
+	mappings.CustomScopeMappings[schema.GroupVersionKind{Version: "v1", Kind: "Pod"}] = true
+
+	plan := PlanScan(planConfig(t), mappings, nil)
+
+	require.Len(t, plan.Resources, 3)
+	assert.Equal(t, PlannedResource{
+		Version: "v1", Resource: "configmaps", Kind: "ConfigMap", Namespaced: true,
+		Namespace: "compliance", Name: "tailoring", Verb: VerbGet, Variables: []string{"maxPods"},
+	}, plan.Resources[0])
+	assert.Equal(t, PlannedResource{
+		Version: "v1", Resource: "nodes", Kind: "Node", Verb: VerbList, Rules: []string{"pods-labels"},
+	}, plan.Resources[1], "the namespace of a cluster-scoped resource is ignored")
+	assert.Equal(t, PlannedResource{
+		Version: "v1", Resource: "pods", Kind: "Pod", Namespaced: true, Namespace: "apps",
+		Verb: VerbList, Rules: []string{"pods-limits", "pods-labels"},
+	}, plan.Resources[2], "inputs reading the same resource are merged")
+
+	assert.Equal(t, []PlannedFile{
+		{Path: "/etc/kubernetes/manifests", Recursive: true, CheckPermissions: true, Rules: []string{"kubelet-config"}},
+		{Path: "etc/kubernetes/kubelet.conf", Rules: []string{"kubelet-config"}},
+	}, plan.Files)
+	assert.Equal(t, []PlannedCommand{{Service: "sshd", Rules: []string{"sshd"}}}, plan.Commands)
+	assert.Equal(t, []PlannedRequest{{Method: "GET", URL: "https://localhost:10250/healthz", Rules: []string{"sshd"}}}, plan.Requests)
+	assert.Equal(t, []string{"deselected", "review"}, plan.UnevaluatedRules)
+
+	var out bytes.Buffer
+	require.NoError(t, plan.WriteText(&out))
+	assert.Contains(t, out.String(), "v1/nodes")
+	assert.Contains(t, out.String(), "(cluster)")
+	assert.Contains(t, out.String(), "var:maxPods")
+	assert.Contains(t, out.String(), "Not evaluated: deselected,review")
+}
+
+func TestCompositeFetcher_Plan(t *testing.T) {
+	composite := NewCompositeFetcherBuilder().WithFilesystem("/host").Build()
+	plan := composite.Plan(planConfig(t))
+
+	require.Len(t, plan.Files, 2)
+	assert.Equal(t, "/etc/kubernetes/manifests", plan.Files[0].Path)
+	assert.Equal(t, "/host/etc/kubernetes/kubelet.conf", plan.Files[1].Path, "relative paths are resolved against the base path")
+}
@@ -0,0 +1,282 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultRBACName names the generated roles and bindings when RBACOptions.Name is empty
+const DefaultRBACName = "compliance-scanner"
+
+// RBACOptions controls the roles generated by GenerateRBAC
+type RBACOptions struct {
+	// Name of the roles and bindings (DefaultRBACName when empty)
+	Name string
+
+	// ServiceAccount and ServiceAccountNamespace identify the account the scanner runs as
+	ServiceAccount          string
+	ServiceAccountNamespace string
+
+	// Variables lists the scan variables; objects referenced by variables must be readable too
+	Variables []scanner.CelVariable
+
+	// Watch grants list and watch on every resource read by rules or
+	// variables, as needed by the informers of ComplianceWatcher, instead of
+	// get and list: in the namespaces read when every access to the resource
+	// names a namespace, across the cluster otherwise. Subresources are not
+	// watched and grant nothing.
+	Watch bool
+
+	// Mappings and DiscoveryClient resolve the scope of resources, as for the
+	// fetcher (default mappings and no discovery when unset)
+	Mappings        *ResourceMappingConfig
+	DiscoveryClient discovery.DiscoveryInterface
+}
+
+// RBACManifests holds the roles and bindings a scanner needs. Cluster-scoped
+// resources and resources read across all namespaces are granted by the
+// ClusterRole; resources read in a single namespace by a Role in that namespace.
+type RBACManifests struct {
+	ClusterRole        *rbacv1.ClusterRole        `json:"clusterRole,omitempty"`
+	ClusterRoleBinding *rbacv1.ClusterRoleBinding `json:"clusterRoleBinding,omitempty"`
+	Roles              []rbacv1.Role              `json:"roles,omitempty"`
+	RoleBindings       []rbacv1.RoleBinding       `json:"roleBindings,omitempty"`
+}
+
+// rbacResource is a resource, or subresource, of an API group
+type rbacResource struct {
+	group    string
+	resource string
+}
+
+// rbacScope collects the accesses made in a namespace, or cluster-wide
+type rbacScope struct {
+	listed map[rbacResource]bool
+	named  map[rbacResource][]string // Names of objects read with get
+}
+
+// GenerateRBAC returns the least-privilege roles needed to fetch the
+// Kubernetes inputs of rules and the objects referenced by variables: get
+// restricted to the object names for inputs naming an object, list for
+// lists, with scopes resolved like PlanScan. Manual rules read nothing and
+// grant nothing.
+func GenerateRBAC(rules []scanner.Rule, options RBACOptions) (RBACManifests, error) {
+	if options.ServiceAccount == "" || options.ServiceAccountNamespace == "" {
+		return RBACManifests{}, fmt.Errorf("a service account name and namespace are required")
+	}
+	if options.Name == "" {
+		options.Name = DefaultRBACName
+	}
+	if options.Mappings == nil {
+		options.Mappings = DefaultResourceMappingConfig()
+	}
+
+	planner := &scanPlanner{mappings: options.Mappings, discoveryClient: options.DiscoveryClient}
+	plan := planner.build(scanner.ScanConfig{Rules: rules, Variables: options.Variables})
+
+	scopes := make(map[string]*rbacScope)
+	scopeFor := func(namespace string) *rbacScope {
+		scope, ok := scopes[namespace]
+		if !ok {
+			scope = &rbacScope{listed: make(map[rbacResource]bool), named: make(map[rbacResource][]string)}
+			scopes[namespace] = scope
+		}
+		return scope
+	}
+
+	// Informers watch a resource across the cluster as soon as one access to
+	// it spans every namespace, like ComplianceWatcher does
+	clusterWide := make(map[rbacResource]bool)
+	for _, planned := range plan.Resources {
+		if planned.Namespace == "" && planned.Subresource == "" {
+			clusterWide[rbacResource{group: planned.Group, resource: planned.Resource}] = true
+		}
+	}
+
+	for _, planned := range plan.Resources {
+		resource := rbacResource{group: planned.Group, resource: planned.Resource}
+		if options.Watch {
+			if planned.Subresource == "" {
+				namespace := planned.Namespace
+				if clusterWide[resource] {
+					namespace = ""
+				}
+				scopeFor(namespace).listed[resource] = true
+			}
+			continue
+		}
+		if planned.Subresource != "" {
+			resource.resource += "/" + planned.Subresource
+		}
+		scope := scopeFor(planned.Namespace)
+		if planned.Verb == VerbGet {
+			scope.named[resource] = appendUnique(scope.named[resource], planned.Name)
+		} else {
+			scope.listed[resource] = true
+		}
+	}
+
+	listVerbs := []string{VerbList}
+	if options.Watch {
+		listVerbs = []string{VerbList, "watch"}
+	}
+	subjects := []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      options.ServiceAccount,
+		Namespace: options.ServiceAccountNamespace,
+	}}
+
+	var manifests RBACManifests
+	if scope, ok := scopes[""]; ok {
+		manifests.ClusterRole = &rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: options.Name},
+			Rules:      scope.policyRules(listVerbs),
+		}
+		manifests.ClusterRoleBinding = &rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: options.Name},
+			Subjects:   subjects,
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: options.Name},
+		}
+	}
+
+	namespaces := make([]string, 0, len(scopes))
+	for namespace := range scopes {
+		if namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		manifests.Roles = append(manifests.Roles, rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+			ObjectMeta: metav1.ObjectMeta{Name: options.Name, Namespace: namespace},
+			Rules:      scopes[namespace].policyRules(listVerbs),
+		})
+		manifests.RoleBindings = append(manifests.RoleBindings, rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: options.Name, Namespace: namespace},
+			Subjects:   subjects,
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: options.Name},
+		})
+	}
+
+	return manifests, nil
+}
+
+// policyRules returns one rule per API group for listed resources, followed
+// by one rule per resource read by name
+func (s *rbacScope) policyRules(listVerbs []string) []rbacv1.PolicyRule {
+	var rules []rbacv1.PolicyRule
+
+	listedByGroup := make(map[string][]string)
+	for resource := range s.listed {
+		listedByGroup[resource.group] = append(listedByGroup[resource.group], resource.resource)
+	}
+	groups := make([]string, 0, len(listedByGroup))
+	for group := range listedByGroup {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		resources := listedByGroup[group]
+		sort.Strings(resources)
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: resources,
+			Verbs:     listVerbs,
+		})
+	}
+
+	named := make([]rbacResource, 0, len(s.named))
+	for resource := range s.named {
+		named = append(named, resource)
+	}
+	sort.Slice(named, func(i, j int) bool {
+		if named[i].group != named[j].group {
+			return named[i].group < named[j].group
+		}
+		return named[i].resource < named[j].resource
+	})
+	for _, resource := range named {
+		names := append([]string(nil), s.named[resource]...)
+		sort.Strings(names)
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{resource.group},
+			Resources:     []string{resource.resource},
+			ResourceNames: names,
+			Verbs:         []string{VerbGet},
+		})
+	}
+
+	return rules
+}
+
+// YAML renders the roles and bindings as a multi-document YAML stream, ready
+// for kubectl apply
+func (m RBACManifests) YAML() ([]byte, error) {
+	var objects []interface{}
+	if m.ClusterRole != nil {
+		objects = append(objects, m.ClusterRole)
+	}
+	if m.ClusterRoleBinding != nil {
+		objects = append(objects, m.ClusterRoleBinding)
+	}
+	for i := range m.Roles {
+		objects = append(objects, &m.Roles[i], &m.RoleBindings[i])
+	}
+
+	var out bytes.Buffer
+	for i, object := range objects {
+		document, err := manifestYAML(object)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		out.Write(document)
+	}
+	return out.Bytes(), nil
+}
+
+// manifestYAML encodes an object without the empty creation timestamp
+// every typed object carries
+func manifestYAML(object interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if metadata, ok := fields["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+	return yaml.Marshal(fields)
+}
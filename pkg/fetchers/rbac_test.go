@@ -0,0 +1,172 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// rbacRules reads nodes, pods in a namespace, secrets in every namespace,
+// a named deployment and the logs of a pod
+func rbacRules(t *testing.T) []scanner.Rule {
+	build := func(builder *scanner.RuleBuilder) scanner.Rule {
+		rule, err := builder.Build()
+		require.NoError(t, err)
+		return rule
+	}
+	return []scanner.Rule{
+		build(scanner.NewRuleBuilder("nodes", scanner.RuleTypeCEL).
+			WithKubernetesInput("nodes", "", "v1", "nodes", "", "").
+			SetCelExpression("nodes.items.size() > 0")),
+		build(scanner.NewRuleBuilder("pods", scanner.RuleTypeCEL).
+			WithKubernetesInput("pods", "", "v1", "pods", "apps", "").
+			WithKubernetesInput("secrets", "", "v1", "secrets", "", "").
+			SetCelExpression("pods.items.size() >= secrets.items.size()")),
+		build(scanner.NewRuleBuilder("deployment", scanner.RuleTypeCEL).
+			WithKubernetesInput("web", "apps", "v1", "deployments", "apps", "web").
+			WithKubernetesInput("log", "", "v1", "pods/log", "apps", "web-0").
+			SetCelExpression("web.spec.replicas > 1")),
+		build(scanner.NewRuleBuilder("review", scanner.RuleTypeCEL).
+			WithKubernetesInput("roles", "rbac.authorization.k8s.io", "v1", "roles", "", "").
+			WithCheckKind(scanner.CheckKindManual).
+			SetCelExpression("true")),
+	}
+}
+
+// rbacMappings resolves kinds and scopes without discovery
+func rbacMappings() *ResourceMappingConfig {
+	mappings := DefaultResourceMappingConfig()
+	for resource, kind := range map[string]string{"nodes": "Node", "pods": "Pod", "secrets": "Secret", "deployments": "Deployment"} {
+		mappings.CustomKindMappings[resource] = kind
+	}
+	mappings.CustomScopeMappings[schema.GroupVersionKind{Version: "v1", Kind: "Node"}] = false
+	return mappings
+}
+
+func TestGenerateRBAC(t *testing.T) {
+	manifests, err := GenerateRBAC(rbacRules(t), RBACOptions{
+		ServiceAccount:          "scanner",
+		ServiceAccountNamespace: "compliance",
+		Variables: []scanner.CelVariable{
+			scanner.NewObjectCelVariable("maxPods", configMapGVK, "compliance", "tailoring", "data.maxPods", scanner.VariableTypeInt),
+		},
+		Mappings: rbacMappings(),
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, manifests.ClusterRole)
+	assert.Equal(t, DefaultRBACName, manifests.ClusterRole.Name)
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"nodes", "secrets"}, Verbs: []string{"list"}},
+	}, manifests.ClusterRole.Rules, "cluster-scoped resources and lists across namespaces need a ClusterRole")
+	require.NotNil(t, manifests.ClusterRoleBinding)
+	assert.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Name: "scanner", Namespace: "compliance"}}, manifests.ClusterRoleBinding.Subjects)
+	assert.Equal(t, "ClusterRole", manifests.ClusterRoleBinding.RoleRef.Kind)
+
+	require.Len(t, manifests.Roles, 2)
+	require.Len(t, manifests.RoleBindings, 2)
+	assert.Equal(t, "apps", manifests.Roles[0].Namespace)
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list"}},
+		{APIGroups: []string{""}, Resources: []string{"pods/log"}, ResourceNames: []string{"web-0"}, Verbs: []string{"get"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, ResourceNames: []string{"web"}, Verbs: []string{"get"}},
+	}, manifests.Roles[0].Rules)
+	assert.Equal(t, "compliance", manifests.Roles[1].Namespace)
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"tailoring"}, Verbs: []string{"get"}},
+	}, manifests.Roles[1].Rules, "objects referenced by variables are readable")
+	assert.Equal(t, rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: DefaultRBACName}, manifests.RoleBindings[1].RoleRef)
+
+	t.Run("yaml", func(t *testing.T) {
+		out, err := manifests.YAML()
+		require.NoError(t, err)
+		assert.NotContains(t, string(out), "creationTimestamp")
+
+		var role rbacv1.Role
+		documents := strings.Split(string(out), "---\n")
+		require.Len(t, documents, 6)
+		require.NoError(t, yaml.Unmarshal([]byte(documents[2]), &role))
+		assert.Equal(t, "Role", role.Kind)
+		assert.Equal(t, manifests.Roles[0].Rules, role.Rules)
+	})
+}
+
+func TestGenerateRBAC_Watch(t *testing.T) {
+	manifests, err := GenerateRBAC(rbacRules(t), RBACOptions{
+		Name:                    "watcher",
+		ServiceAccount:          "scanner",
+		ServiceAccountNamespace: "compliance",
+		Variables: []scanner.CelVariable{
+			scanner.NewObjectCelVariable("maxPods", configMapGVK, "compliance", "tailoring", "data.maxPods", scanner.VariableTypeInt),
+		},
+		Watch:    true,
+		Mappings: rbacMappings(),
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, manifests.ClusterRole)
+	assert.Equal(t, "watcher", manifests.ClusterRole.Name)
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"nodes", "secrets"}, Verbs: []string{"list", "watch"}},
+	}, manifests.ClusterRole.Rules, "resources read across namespaces are watched across the cluster")
+
+	require.Len(t, manifests.Roles, 2)
+	assert.Equal(t, "apps", manifests.Roles[0].Namespace)
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list", "watch"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"list", "watch"}},
+	}, manifests.Roles[0].Rules, "resources read in a namespace are watched in that namespace; subresources are not watched")
+	assert.Equal(t, "compliance", manifests.Roles[1].Namespace)
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"list", "watch"}},
+	}, manifests.Roles[1].Rules, "objects referenced by variables are watched")
+
+	t.Run("cluster-wide access", func(t *testing.T) {
+		rule, err := scanner.NewRuleBuilder("all-pods", scanner.RuleTypeCEL).
+			WithKubernetesInput("pods", "", "v1", "pods", "", "").
+			SetCelExpression("pods.items.size() > 0").
+			Build()
+		require.NoError(t, err)
+
+		manifests, err := GenerateRBAC(append(rbacRules(t), rule), RBACOptions{
+			ServiceAccount:          "scanner",
+			ServiceAccountNamespace: "compliance",
+			Watch:                   true,
+			Mappings:                rbacMappings(),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"nodes", "pods", "secrets"}, Verbs: []string{"list", "watch"}},
+		}, manifests.ClusterRole.Rules, "one access across namespaces watches the resource across the cluster")
+		require.Len(t, manifests.Roles, 1)
+		assert.Equal(t, []rbacv1.PolicyRule{
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"list", "watch"}},
+		}, manifests.Roles[0].Rules)
+	})
+}
+
+func TestGenerateRBAC_RequiresServiceAccount(t *testing.T) {
+	_, err := GenerateRBAC(rbacRules(t), RBACOptions{ServiceAccount: "scanner"})
+	assert.Error(t, err)
+}
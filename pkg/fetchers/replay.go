@@ -0,0 +1,629 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+// Layout of a snapshot directory
+const (
+	snapshotVersion       = "1"
+	snapshotManifestFile  = "manifest.json"
+	snapshotKubernetesDir = "kubernetes" // Read by NewKubernetesFileFetcher
+	snapshotFilesDir      = "files"
+)
+
+// SnapshotManifest describes a snapshot written by RecordingFetcher: what
+// was known about the cluster and the variables of the recorded scan
+type SnapshotManifest struct {
+	Version   string              `json:"version"`
+	CreatedAt time.Time           `json:"createdAt"`
+	Cluster   scanner.ClusterInfo `json:"cluster"`
+
+	// Resources records the scope of every Kubernetes resource in the
+	// snapshot, as discovered when it was recorded
+	Resources []SnapshotResource `json:"resources,omitempty"`
+
+	// Variables lists the variables handed to the fetcher, already resolved
+	Variables []scanner.CelVariableImpl `json:"variables,omitempty"`
+
+	// References lists the values read for variables referencing objects
+	References []scanner.CelVariableImpl `json:"references,omitempty"`
+}
+
+// SnapshotResource records whether a Kubernetes resource is namespaced
+type SnapshotResource struct {
+	Group      string `json:"group,omitempty"`
+	Version    string `json:"version"`
+	Resource   string `json:"resource"`
+	Namespaced bool   `json:"namespaced"`
+}
+
+// snapshotFile is the recorded content of a file input
+type snapshotFile struct {
+	Path             string      `json:"path"`
+	Format           string      `json:"format,omitempty"`
+	Recursive        bool        `json:"recursive,omitempty"`
+	CheckPermissions bool        `json:"checkPermissions,omitempty"`
+	Data             interface{} `json:"data"`
+}
+
+// matches reports whether the recorded file answers spec
+func (f *snapshotFile) matches(spec scanner.FileInputSpec) bool {
+	return f.Path == spec.Path() && f.Format == spec.Format() &&
+		f.Recursive == spec.Recursive() && f.CheckPermissions == spec.CheckPermissions()
+}
+
+// snapshotList accumulates the objects of a Kubernetes resource file
+type snapshotList struct {
+	apiVersion string
+	kind       string
+	items      []interface{}
+}
+
+// setItems stores a complete list, keeping objects recorded individually
+// that the list does not contain
+func (l *snapshotList) setItems(items []interface{}) {
+	listed := make(map[string]bool, len(items))
+	for _, item := range items {
+		listed[objectIdentity(item)] = true
+	}
+	merged := append([]interface{}(nil), items...)
+	for _, item := range l.items {
+		if !listed[objectIdentity(item)] {
+			merged = append(merged, item)
+		}
+	}
+	l.items = merged
+}
+
+// addItem stores a single object, replacing a recorded object with the same identity
+func (l *snapshotList) addItem(object interface{}) {
+	identity := objectIdentity(object)
+	for i, item := range l.items {
+		if objectIdentity(item) == identity {
+			l.items[i] = object
+			return
+		}
+	}
+	l.items = append(l.items, object)
+}
+
+// objectIdentity returns the namespace and name of a Kubernetes object
+func objectIdentity(object interface{}) string {
+	typed, _ := object.(map[string]interface{})
+	metadata, _ := typed["metadata"].(map[string]interface{})
+	namespace, _ := metadata["namespace"].(string)
+	name, _ := metadata["name"].(string)
+	return namespace + "/" + name
+}
+
+// RecordingFetcher fetches inputs through another fetcher and records them,
+// along with variables and cluster information, so that WriteSnapshot can
+// store everything a scan read. Kubernetes inputs are stored in the layout
+// read by NewKubernetesFileFetcher; inputs of other types than Kubernetes and
+// file inputs are not recorded.
+type RecordingFetcher struct {
+	fetcher    scanner.ResourceFetcher
+	kubernetes *KubernetesFetcher // Decides the scope of recorded resources (optional)
+
+	mu         sync.Mutex
+	cluster    scanner.ClusterInfo
+	resources  map[SnapshotResource]bool
+	lists      map[string]*snapshotList   // Keyed by file path in the Kubernetes layout
+	files      map[string][]*snapshotFile // Keyed by file path in the snapshot
+	variables  map[string]scanner.CelVariableImpl
+	references map[string]scanner.CelVariableImpl
+}
+
+// NewRecordingFetcher returns a fetcher recording everything fetched through fetcher
+func NewRecordingFetcher(fetcher scanner.ResourceFetcher) *RecordingFetcher {
+	recorder := &RecordingFetcher{
+		fetcher:    fetcher,
+		resources:  make(map[SnapshotResource]bool),
+		lists:      make(map[string]*snapshotList),
+		files:      make(map[string][]*snapshotFile),
+		variables:  make(map[string]scanner.CelVariableImpl),
+		references: make(map[string]scanner.CelVariableImpl),
+	}
+	if composite, ok := fetcher.(*CompositeFetcher); ok {
+		recorder.kubernetes = composite.kubernetesFetcher
+	}
+	return recorder
+}
+
+// FetchResources fetches the inputs of rule and records the fetched data
+func (r *RecordingFetcher) FetchResources(ctx context.Context, rule scanner.Rule, variables []scanner.CelVariable) (map[string]interface{}, []string, error) {
+	data, warnings, absent, err := r.fetch(ctx, rule, variables)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, variable := range variables {
+		r.variables[variable.Name()] = snapshotVariable(variable, variable.Value())
+	}
+	if err != nil {
+		return data, warnings, err
+	}
+
+	for _, input := range rule.Inputs() {
+		value, ok := data[input.Name()]
+		if !ok || absent[input.Name()] {
+			// Missing inputs are missing again on replay
+			continue
+		}
+		switch spec := input.Spec().(type) {
+		case scanner.KubernetesInputSpec:
+			r.recordKubernetes(spec, value)
+		case scanner.FileInputSpec:
+			r.recordFile(spec, value)
+		}
+	}
+	return data, warnings, nil
+}
+
+// fetch fetches the inputs of rule, returning the names of the optional
+// inputs that were not found. Optional inputs are fetched one at a time as
+// required inputs, so that a missing input is told apart by its error rather
+// than by the absent value the fetcher would bind.
+func (r *RecordingFetcher) fetch(ctx context.Context, rule scanner.Rule, variables []scanner.CelVariable) (map[string]interface{}, []string, map[string]bool, error) {
+	var required, optional []scanner.Input
+	for _, input := range rule.Inputs() {
+		if scanner.IsOptionalInput(input) {
+			optional = append(optional, input)
+		} else {
+			required = append(required, input)
+		}
+	}
+	if len(optional) == 0 {
+		data, warnings, err := r.fetcher.FetchResources(ctx, rule, variables)
+		return data, warnings, nil, err
+	}
+
+	data := make(map[string]interface{})
+	absent := make(map[string]bool)
+	var warnings []string
+	if len(required) > 0 {
+		fetched, fetchWarnings, err := r.fetcher.FetchResources(ctx, &inputsRule{Rule: rule, inputs: required}, variables)
+		warnings = append(warnings, fetchWarnings...)
+		if err != nil {
+			return nil, warnings, nil, err
+		}
+		for name, value := range fetched {
+			data[name] = value
+		}
+	}
+	for _, input := range optional {
+		fetched, fetchWarnings, err := r.fetcher.FetchResources(ctx, &inputsRule{Rule: rule, inputs: []scanner.Input{requiredInput{input}}}, variables)
+		warnings = append(warnings, fetchWarnings...)
+		if err != nil && scanner.IsMissingInputError(err) {
+			warnings = append(warnings, scanner.AbsentInputWarning(input, err))
+			data[input.Name()] = scanner.AbsentInputValue(input)
+			absent[input.Name()] = true
+			continue
+		}
+		if err != nil {
+			return nil, warnings, nil, err
+		}
+		if value, ok := fetched[input.Name()]; ok {
+			data[input.Name()] = value
+		}
+	}
+	return data, warnings, absent, nil
+}
+
+// inputsRule is a rule restricted to some of its inputs
+type inputsRule struct {
+	scanner.Rule
+	inputs []scanner.Input
+}
+
+func (r *inputsRule) Inputs() []scanner.Input {
+	return r.inputs
+}
+
+// requiredInput hides the optionality of an input, so that fetchers fail
+// when it is missing
+type requiredInput struct {
+	scanner.Input
+}
+
+// recordKubernetes adds fetched objects to the file NewKubernetesFileFetcher reads them from
+func (r *RecordingFetcher) recordKubernetes(spec scanner.KubernetesInputSpec, data interface{}) {
+	namespaced := IsNamespaced(spec)
+	if r.kubernetes != nil {
+		namespaced = IsNamespacedWithConfig(spec, r.kubernetes.discoveryClient, r.kubernetes.config)
+	}
+	r.resources[SnapshotResource{
+		Group:      spec.ApiGroup(),
+		Version:    spec.Version(),
+		Resource:   spec.ResourceType(),
+		Namespaced: namespaced,
+	}] = true
+
+	file := spec.ResourceType() + ".json"
+	if namespaced && spec.Namespace() != "" {
+		file = filepath.Join("namespaces", spec.Namespace(), file)
+	}
+	list, ok := r.lists[file]
+	if !ok {
+		list = &snapshotList{apiVersion: "v1", kind: "List"}
+		r.lists[file] = list
+	}
+
+	object, _ := data.(map[string]interface{})
+	if spec.Name() != "" {
+		list.addItem(object)
+		return
+	}
+	if apiVersion, ok := object["apiVersion"].(string); ok && apiVersion != "" {
+		list.apiVersion = apiVersion
+	}
+	if kind, ok := object["kind"].(string); ok && kind != "" {
+		list.kind = kind
+	}
+	items, _ := object["items"].([]interface{})
+	list.setItems(items)
+}
+
+// recordFile stores the data read for a file input
+func (r *RecordingFetcher) recordFile(spec scanner.FileInputSpec, data interface{}) {
+	file := snapshotFilePath(spec.Path())
+	for _, recorded := range r.files[file] {
+		if recorded.matches(spec) {
+			recorded.Data = data
+			return
+		}
+	}
+	r.files[file] = append(r.files[file], &snapshotFile{
+		Path:             spec.Path(),
+		Format:           spec.Format(),
+		Recursive:        spec.Recursive(),
+		CheckPermissions: spec.CheckPermissions(),
+		Data:             data,
+	})
+}
+
+// snapshotFilePath returns where a file input is stored in the files
+// directory: its path, confined to the directory, with a .json extension.
+// Files whose paths clean to the same name share the file.
+func snapshotFilePath(filePath string) string {
+	cleaned := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(filePath)), "/")
+	return filepath.FromSlash(cleaned) + ".json"
+}
+
+// ResolveVariable resolves variable through the wrapped fetcher and records its value
+func (r *RecordingFetcher) ResolveVariable(ctx context.Context, variable scanner.ObjectReferenceVariable) (string, error) {
+	resolver, ok := r.fetcher.(scanner.VariableResolver)
+	if !ok {
+		return "", fmt.Errorf("fetcher cannot resolve variables referencing objects")
+	}
+	value, err := resolver.ResolveVariable(ctx, variable)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.references[referenceKey(variable)] = snapshotVariable(variable, value)
+	return value, nil
+}
+
+// ClusterInfo describes the cluster through the wrapped fetcher and records the description
+func (r *RecordingFetcher) ClusterInfo(ctx context.Context) (scanner.ClusterInfo, error) {
+	provider, ok := r.fetcher.(scanner.ClusterInfoProvider)
+	if !ok {
+		return scanner.ClusterInfo{}, nil
+	}
+	info, err := provider.ClusterInfo(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cluster = info
+	return info, err
+}
+
+// snapshotVariable records a variable with the value it had during the scan
+func snapshotVariable(variable scanner.CelVariable, value string) scanner.CelVariableImpl {
+	recorded := scanner.CelVariableImpl{
+		VarName:      variable.Name(),
+		VarNamespace: variable.Namespace(),
+		VarValue:     value,
+		GVK:          variable.GroupVersionKind(),
+	}
+	if typed, ok := variable.(scanner.TypedCelVariable); ok {
+		recorded.VarType = typed.Type()
+	}
+	if ref, ok := variable.(scanner.ObjectReferenceVariable); ok {
+		recorded.RefName = ref.ObjectName()
+		recorded.RefPath = ref.FieldPath()
+	}
+	return recorded
+}
+
+// referenceKey identifies the object field a variable references
+func referenceKey(variable scanner.ObjectReferenceVariable) string {
+	gvk := variable.GroupVersionKind()
+	return strings.Join([]string{gvk.Group, gvk.Version, gvk.Kind, variable.Namespace(), variable.ObjectName(), variable.FieldPath()}, "|")
+}
+
+// WriteSnapshot writes everything recorded so far to dir: Kubernetes
+// resources under kubernetes/, file inputs under files/ and the cluster,
+// resource scopes and variables in manifest.json. The snapshot is replayed
+// with NewReplayFetcher.
+func (r *RecordingFetcher) WriteSnapshot(dir string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	writeFile := func(name string, value interface{}) error {
+		encoded, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		filePath := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return fmt.Errorf("failed to create snapshot directory: %w", err)
+		}
+		if err := os.WriteFile(filePath, encoded, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		return nil
+	}
+
+	for file, list := range r.lists {
+		content := map[string]interface{}{
+			"apiVersion": list.apiVersion,
+			"kind":       list.kind,
+			"items":      snapshotValue(list.items),
+		}
+		if err := writeFile(filepath.Join(snapshotKubernetesDir, file), content); err != nil {
+			return err
+		}
+	}
+
+	for file, recorded := range r.files {
+		entries := make([]snapshotFile, len(recorded))
+		for i, entry := range recorded {
+			entries[i] = *entry
+			entries[i].Data = snapshotValue(entry.Data)
+		}
+		if err := writeFile(filepath.Join(snapshotFilesDir, file), entries); err != nil {
+			return err
+		}
+	}
+
+	manifest := SnapshotManifest{
+		Version:   snapshotVersion,
+		CreatedAt: time.Now().UTC(),
+		Cluster:   r.cluster,
+	}
+	for resource := range r.resources {
+		manifest.Resources = append(manifest.Resources, resource)
+	}
+	sort.Slice(manifest.Resources, func(i, j int) bool {
+		a, b := manifest.Resources[i], manifest.Resources[j]
+		return a.Group+"/"+a.Version+"/"+a.Resource < b.Group+"/"+b.Version+"/"+b.Resource
+	})
+	manifest.Variables = sortedVariables(r.variables)
+	manifest.References = sortedVariables(r.references)
+
+	// The manifest is written last so that a snapshot is only complete with it
+	return writeFile(snapshotManifestFile, manifest)
+}
+
+// sortedVariables returns recorded variables ordered by key
+func sortedVariables(variables map[string]scanner.CelVariableImpl) []scanner.CelVariableImpl {
+	keys := make([]string, 0, len(variables))
+	for key := range variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sorted := make([]scanner.CelVariableImpl, 0, len(keys))
+	for _, key := range keys {
+		sorted = append(sorted, variables[key])
+	}
+	return sorted
+}
+
+// snapshotValue copies data for encoding, writing whole floats with a decimal
+// point so that they are read back as floats while integers stay integers
+func snapshotValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(typed))
+		for key, element := range typed {
+			copied[key] = snapshotValue(element)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(typed))
+		for i, element := range typed {
+			copied[i] = snapshotValue(element)
+		}
+		return copied
+	case float64:
+		if math.IsInf(typed, 0) || math.IsNaN(typed) {
+			return typed
+		}
+		formatted := strconv.FormatFloat(typed, 'g', -1, 64)
+		if !strings.ContainsAny(formatted, ".eE") {
+			formatted += ".0"
+		}
+		return json.Number(formatted)
+	default:
+		return value
+	}
+}
+
+// ReplayFetcher serves the inputs, variables and cluster information of a
+// snapshot written by RecordingFetcher, so that the recorded scan can be run
+// again offline
+type ReplayFetcher struct {
+	*CompositeFetcher
+
+	manifest   SnapshotManifest
+	references map[string]string
+}
+
+// NewReplayFetcher opens the snapshot in dir
+func NewReplayFetcher(dir string) (*ReplayFetcher, error) {
+	content, err := os.ReadFile(filepath.Join(dir, snapshotManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot manifest: %w", err)
+	}
+	var manifest SnapshotManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot manifest: %w", err)
+	}
+	if manifest.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %q", manifest.Version)
+	}
+
+	// Resources keep the scope they were recorded with, without discovery
+	config := DefaultResourceMappingConfig()
+	for _, resource := range manifest.Resources {
+		spec := &scanner.KubernetesInput{Group: resource.Group, Ver: resource.Version, ResType: resource.Resource}
+		config.CustomScopeMappings[GetGVKWithConfig(spec, config, nil)] = resource.Namespaced
+	}
+
+	composite := NewCompositeFetcher()
+	composite.SetKubernetesFetcher(NewKubernetesFileFetcher(filepath.Join(dir, snapshotKubernetesDir)).WithConfig(config))
+	composite.RegisterCustomFetcher(scanner.InputTypeFile, &snapshotFileFetcher{dir: filepath.Join(dir, snapshotFilesDir)})
+
+	replay := &ReplayFetcher{
+		CompositeFetcher: composite,
+		manifest:         manifest,
+		references:       make(map[string]string, len(manifest.References)),
+	}
+	for i := range manifest.References {
+		replay.references[referenceKey(&manifest.References[i])] = manifest.References[i].VarValue
+	}
+	return replay, nil
+}
+
+// Manifest returns the manifest of the snapshot
+func (r *ReplayFetcher) Manifest() SnapshotManifest {
+	return r.manifest
+}
+
+// Variables returns the variables of the recorded scan, with the values they
+// were resolved to, to be used as ScanConfig.Variables
+func (r *ReplayFetcher) Variables() []scanner.CelVariable {
+	variables := make([]scanner.CelVariable, len(r.manifest.Variables))
+	for i := range r.manifest.Variables {
+		variable := r.manifest.Variables[i]
+		variables[i] = &variable
+	}
+	return variables
+}
+
+// ResolveVariable returns the value recorded for the object field variable references
+func (r *ReplayFetcher) ResolveVariable(ctx context.Context, variable scanner.ObjectReferenceVariable) (string, error) {
+	value, ok := r.references[referenceKey(variable)]
+	if !ok {
+		return "", fmt.Errorf("variable %s was not recorded in the snapshot", variable.Name())
+	}
+	return value, nil
+}
+
+// ClusterInfo returns the cluster the snapshot was recorded from
+func (r *ReplayFetcher) ClusterInfo(ctx context.Context) (scanner.ClusterInfo, error) {
+	return r.manifest.Cluster, nil
+}
+
+// snapshotFileFetcher serves file inputs recorded in a snapshot
+type snapshotFileFetcher struct {
+	dir string
+}
+
+// FetchInputs retrieves recorded file inputs
+func (f *snapshotFileFetcher) FetchInputs(inputs []scanner.Input, variables []scanner.CelVariable) (map[string]interface{}, error) {
+	result, _, err := f.FetchInputsWithContext(context.Background(), inputs, variables)
+	return result, err
+}
+
+// FetchInputsWithContext retrieves recorded file inputs. Files missing from
+// the snapshot are reported as not found, like files missing from a host.
+func (f *snapshotFileFetcher) FetchInputsWithContext(ctx context.Context, inputs []scanner.Input, variables []scanner.CelVariable) (map[string]interface{}, []string, error) {
+	result := make(map[string]interface{})
+	var warnings []string
+
+	for _, input := range inputs {
+		if err := ctx.Err(); err != nil {
+			return nil, warnings, err
+		}
+
+		spec, ok := input.Spec().(scanner.FileInputSpec)
+		if !ok {
+			return nil, warnings, fmt.Errorf("invalid file input spec for input %s", input.Name())
+		}
+
+		data, err := f.readFile(spec)
+		if err != nil && scanner.IsOptionalInput(input) && scanner.IsMissingInputError(err) {
+			warnings = append(warnings, scanner.AbsentInputWarning(input, err))
+			result[input.Name()] = scanner.AbsentInputValue(input)
+			continue
+		}
+		if err != nil {
+			return nil, warnings, fmt.Errorf("failed to fetch file resource for input %s: %w", input.Name(), err)
+		}
+		result[input.Name()] = data
+	}
+
+	return result, warnings, nil
+}
+
+// SupportsInputType returns true for file input types
+func (f *snapshotFileFetcher) SupportsInputType(inputType scanner.InputType) bool {
+	return inputType == scanner.InputTypeFile
+}
+
+// readFile returns the data recorded for spec
+func (f *snapshotFileFetcher) readFile(spec scanner.FileInputSpec) (interface{}, error) {
+	filePath := filepath.Join(f.dir, snapshotFilePath(spec.Path()))
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("file %s is not recorded in the snapshot: %w", spec.Path(), err)
+	}
+
+	var recorded []snapshotFile
+	if err := utiljson.Unmarshal(content, &recorded); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	for _, entry := range recorded {
+		if entry.matches(spec) {
+			return entry.Data, nil
+		}
+	}
+	return nil, fmt.Errorf("file %s is not recorded in the snapshot: %w", spec.Path(), fs.ErrNotExist)
+}
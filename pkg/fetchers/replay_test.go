@@ -0,0 +1,242 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeReplaySources stores pre-fetched pods next to the tailoring ConfigMap,
+// and configuration files, standing in for a live cluster and host
+func writeReplaySources(t *testing.T) (string, string) {
+	apiResourcePath := writeTailoringConfigMap(t)
+	pod := func(name string, priority int, labels map[string]interface{}) interface{} {
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name, "namespace": "default", "labels": labels},
+			"spec":     map[string]interface{}{"priority": priority},
+		}
+	}
+	pods, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "PodList",
+		"items": []interface{}{
+			pod("web", 10, map[string]interface{}{"app": "web"}),
+			pod("db", 20, nil),
+		},
+	})
+	require.NoError(t, err)
+	dir := filepath.Join(apiResourcePath, "namespaces", "default")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pods.json"), pods, 0644))
+
+	filesPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(filesPath, "sshd.yaml"), []byte("port: 22\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(filesPath, "settings.json"), []byte(`{"ratio": 1.0, "retries": 3}`), 0600))
+	return apiResourcePath, filesPath
+}
+
+// replayRules reads every kind of recorded data
+func replayRules(t *testing.T) []scanner.Rule {
+	build := func(builder *scanner.RuleBuilder) scanner.Rule {
+		rule, err := builder.Build()
+		require.NoError(t, err)
+		return rule
+	}
+	return []scanner.Rule{
+		build(scanner.NewRuleBuilder("pod-priority", scanner.RuleTypeCEL).
+			WithKubernetesInput("pods", "", "v1", "pods", "default", "").
+			SetCelExpression("pods.items.all(p, p.spec.priority + 1 > 10)")),
+		build(scanner.NewRuleBuilder("web-pod", scanner.RuleTypeCEL).
+			WithKubernetesInput("pod", "", "v1", "pods", "default", "web").
+			SetCelExpression("pod.metadata.labels.app == 'web'")),
+		build(scanner.NewRuleBuilder("pods-labelled", scanner.RuleTypeCEL).
+			WithKubernetesInput("pods", "", "v1", "pods", "default", "").
+			SetCelExpression("pods.items.all(p, has(p.metadata.labels.app))").
+			WithMessageExpression("'unlabelled pods: ' + pods.items.filter(p, !has(p.metadata.labels.app)).map(p, p.metadata.name)[0]")),
+		build(scanner.NewRuleBuilder("max-pods", scanner.RuleTypeCEL).
+			WithKubernetesInput("configmaps", "", "v1", "configmaps", "compliance", "").
+			SetCelExpression("configmaps.items.size() <= maxPods")),
+		build(scanner.NewRuleBuilder("sshd-port", scanner.RuleTypeCEL).
+			WithFileInput("sshd", "sshd.yaml", "yaml", false, false).
+			SetCelExpression("sshd.port + 0 == 22")),
+		build(scanner.NewRuleBuilder("settings", scanner.RuleTypeCEL).
+			WithFileInput("settings", "settings.json", "json", false, true).
+			SetCelExpression("settings.perm == '0600' && settings.content.ratio == 1.0 && settings.content.retries + 0.5 > 3.0")),
+		build(scanner.NewRuleBuilder("banner", scanner.RuleTypeCEL).
+			WithFileInput("banner", "banner.txt", "text", false, false).
+			MarkInputOptional("banner").
+			SetCelExpression("banner == null")),
+	}
+}
+
+func TestRecordingFetcher_Replay(t *testing.T) {
+	apiResourcePath, filesPath := writeReplaySources(t)
+	variables := []scanner.CelVariable{
+		scanner.NewObjectCelVariable("maxPods", configMapGVK, "compliance", "tailoring", "data.maxPods", scanner.VariableTypeInt),
+	}
+
+	live := NewCompositeFetcherBuilder().WithKubernetesFiles(apiResourcePath).WithFilesystem(filesPath).Build()
+	recorder := NewRecordingFetcher(live)
+	recorded, err := scanner.NewScanner(recorder, nil).Scan(context.Background(), scanner.ScanConfig{
+		Rules:     replayRules(t),
+		Variables: variables,
+	})
+	require.NoError(t, err)
+
+	snapshot := t.TempDir()
+	require.NoError(t, recorder.WriteSnapshot(snapshot))
+	assert.FileExists(t, filepath.Join(snapshot, "kubernetes", "namespaces", "default", "pods.json"))
+	assert.FileExists(t, filepath.Join(snapshot, "files", "sshd.yaml.json"))
+	assert.NoFileExists(t, filepath.Join(snapshot, "files", "banner.txt.json"))
+
+	replay, err := NewReplayFetcher(snapshot)
+	require.NoError(t, err)
+	replayed, err := scanner.NewScanner(replay, nil).Scan(context.Background(), scanner.ScanConfig{
+		Rules:     replayRules(t),
+		Variables: variables,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, replayed, len(recorded))
+	for i := range recorded {
+		assert.Equal(t, recorded[i].ID, replayed[i].ID)
+		assert.Equal(t, recorded[i].Status, replayed[i].Status, "%s: %s", replayed[i].ID, replayed[i].ErrorMessage)
+		assert.Equal(t, recorded[i].ErrorMessage, replayed[i].ErrorMessage)
+		assert.Equal(t, len(recorded[i].Warnings), len(replayed[i].Warnings), replayed[i].ID)
+	}
+
+	statuses := map[string]scanner.CheckResultStatus{}
+	for _, result := range replayed {
+		statuses[result.ID] = result.Status
+	}
+	assert.Equal(t, map[string]scanner.CheckResultStatus{
+		"pod-priority":  scanner.CheckResultPass,
+		"web-pod":       scanner.CheckResultPass,
+		"pods-labelled": scanner.CheckResultFail,
+		"max-pods":      scanner.CheckResultPass,
+		"sshd-port":     scanner.CheckResultPass,
+		"settings":      scanner.CheckResultPass,
+		"banner":        scanner.CheckResultPass,
+	}, statuses)
+	assert.Equal(t, "unlabelled pods: db", replayed[2].ErrorMessage)
+
+	t.Run("variables", func(t *testing.T) {
+		require.Len(t, replay.Manifest().References, 1)
+		assert.Equal(t, "10", replay.Manifest().References[0].VarValue)
+
+		variables := replay.Variables()
+		require.Len(t, variables, 1)
+		assert.Equal(t, "maxPods", variables[0].Name())
+		assert.Equal(t, "10", variables[0].Value())
+	})
+
+	t.Run("unrecorded inputs are missing", func(t *testing.T) {
+		rule, err := scanner.NewRuleBuilder("nodes", scanner.RuleTypeCEL).
+			WithKubernetesInput("nodes", "", "v1", "nodes", "", "").
+			SetCelExpression("nodes.items.size() > 0").
+			Build()
+		require.NoError(t, err)
+
+		results, err := scanner.NewScanner(replay, nil).Scan(context.Background(), scanner.ScanConfig{Rules: []scanner.Rule{rule}})
+		require.NoError(t, err)
+		assert.Equal(t, scanner.CheckResultError, results[0].Status)
+	})
+}
+
+// warningFetcher is a composite fetcher adding a warning to every fetch
+type warningFetcher struct {
+	*CompositeFetcher
+	warning string
+}
+
+func (f *warningFetcher) FetchResources(ctx context.Context, rule scanner.Rule, variables []scanner.CelVariable) (map[string]interface{}, []string, error) {
+	data, warnings, err := f.CompositeFetcher.FetchResources(ctx, rule, variables)
+	return data, append(warnings, f.warning), err
+}
+
+func TestRecordingFetcher_OptionalInputs(t *testing.T) {
+	_, filesPath := writeReplaySources(t)
+	live := NewCompositeFetcherBuilder().WithFilesystem(filesPath).Build()
+	recorder := NewRecordingFetcher(&warningFetcher{CompositeFetcher: live, warning: "optional input sshd not found: stale"})
+
+	rule, err := scanner.NewRuleBuilder("sshd-banner", scanner.RuleTypeCEL).
+		WithFileInput("sshd", "sshd.yaml", "yaml", false, false).
+		WithFileInput("banner", "banner.txt", "text", false, false).
+		MarkInputOptional("sshd").
+		MarkInputOptional("banner").
+		SetCelExpression("sshd.port == 22 && banner == null").
+		Build()
+	require.NoError(t, err)
+
+	data, _, err := recorder.FetchResources(context.Background(), rule, nil)
+	require.NoError(t, err)
+	assert.Nil(t, data["banner"])
+
+	snapshot := t.TempDir()
+	require.NoError(t, recorder.WriteSnapshot(snapshot))
+	assert.FileExists(t, filepath.Join(snapshot, "files", "sshd.yaml.json"), "found inputs are recorded whatever the warnings say")
+	assert.NoFileExists(t, filepath.Join(snapshot, "files", "banner.txt.json"))
+}
+
+// describedFetcher is a composite fetcher describing a cluster
+type describedFetcher struct {
+	*CompositeFetcher
+}
+
+func (f *describedFetcher) ClusterInfo(ctx context.Context) (scanner.ClusterInfo, error) {
+	return scanner.ClusterInfo{KubernetesVersion: "v1.31.6", Platform: PlatformOpenShift}, nil
+}
+
+func TestRecordingFetcher_ClusterInfo(t *testing.T) {
+	recorder := NewRecordingFetcher(&describedFetcher{CompositeFetcher: NewCompositeFetcher()})
+	_, err := scanner.NewScanner(recorder, nil).Scan(context.Background(), scanner.ScanConfig{})
+	require.NoError(t, err)
+
+	snapshot := t.TempDir()
+	require.NoError(t, recorder.WriteSnapshot(snapshot))
+	replay, err := NewReplayFetcher(snapshot)
+	require.NoError(t, err)
+
+	info, err := replay.ClusterInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, scanner.ClusterInfo{KubernetesVersion: "v1.31.6", Platform: PlatformOpenShift}, info)
+}
+
+func TestSnapshotValue(t *testing.T) {
+	encoded, err := json.Marshal(snapshotValue(map[string]interface{}{
+		"int":   int64(3),
+		"whole": float64(3),
+		"frac":  1.5,
+		"list":  []interface{}{float64(2), "a"},
+	}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"int": 3, "whole": 3.0, "frac": 1.5, "list": [2.0, "a"]}`, string(encoded))
+	assert.Contains(t, string(encoded), `"whole":3.0`)
+}
+
+func TestSnapshotFilePath(t *testing.T) {
+	assert.Equal(t, filepath.Join("etc", "ssh", "sshd_config.json"), snapshotFilePath("/etc/ssh/sshd_config"))
+	assert.Equal(t, "sshd.yaml.json", snapshotFilePath("sshd.yaml"))
+	assert.Equal(t, filepath.Join("etc", "passwd.json"), snapshotFilePath("../../etc/passwd"))
+}
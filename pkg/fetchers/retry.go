@@ -0,0 +1,174 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// RetryPolicy controls how API calls failing with retryable errors are retried
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt (0 disables retries)
+	MaxRetries int
+
+	// InitialBackoff is the delay before the first retry; it doubles on every retry
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between retries, including delays requested by the server
+	MaxBackoff time.Duration
+
+	// Jitter adds a random delay of up to this fraction of the backoff
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns the retry policy used by Kubernetes fetchers by default
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Jitter:         0.2,
+	}
+}
+
+// NoRetryPolicy returns a policy failing on the first error
+func NoRetryPolicy() RetryPolicy {
+	return RetryPolicy{}
+}
+
+// backoff returns the delay before retry number attempt (starting at 0). A
+// delay suggested by the server through Retry-After is honoured up to MaxBackoff.
+func (p RetryPolicy) backoff(attempt int, err error) time.Duration {
+	delay := p.InitialBackoff
+	for i := 0; i < attempt && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if seconds, ok := apierrors.SuggestsClientDelay(err); ok {
+		if suggested := time.Duration(seconds) * time.Second; suggested > delay {
+			delay = suggested
+		}
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delay = wait.Jitter(delay, p.Jitter)
+	}
+	return delay
+}
+
+// IsRetryableError reports whether an API error is likely to be transient:
+// throttling (429), server timeouts, 5xx errors other than 501, and transport
+// failures such as reset connections, unexpected EOFs and network timeouts
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if isTransportError(err) {
+		return true
+	}
+
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		if code := status.Status().Code; code == http.StatusNotImplemented {
+			return false
+		} else if code >= http.StatusInternalServerError {
+			return true
+		}
+	}
+	return apierrors.IsTooManyRequests(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsInternalError(err) || apierrors.IsServiceUnavailable(err) || apierrors.IsUnexpectedServerError(err)
+}
+
+// isTransportError reports whether err is a connection failure the API server
+// did not answer, as opposed to an error status it returned
+func isTransportError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err) || utilnet.IsHTTP2ConnectionLost(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// callAPI runs call, waiting for the rate limiter before every attempt and
+// retrying retryable errors according to the retry policy. It returns the
+// number of retries made.
+func (k *KubernetesFetcher) callAPI(ctx context.Context, call func(ctx context.Context) error) (int, error) {
+	for attempt := 0; ; attempt++ {
+		if k.rateLimiter != nil {
+			if err := k.rateLimiter.Wait(ctx); err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return attempt, ctxErr
+				}
+				// The limiter refuses waits ending after the deadline before it
+				// is reached; report the deadline so that callers do not take
+				// the refusal for a failed fetch
+				return attempt, fmt.Errorf("rate limiter: %v: %w", err, context.DeadlineExceeded)
+			}
+		}
+
+		err := call(ctx)
+		if err == nil || attempt >= k.retryPolicy.MaxRetries || !IsRetryableError(err) {
+			if err != nil && attempt > 0 {
+				err = fmt.Errorf("%w (after %d retries)", err, attempt)
+			}
+			return attempt, err
+		}
+
+		timer := time.NewTimer(k.retryPolicy.backoff(attempt, err))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, ctx.Err()
+		}
+	}
+}
+
+// WithRetryPolicy sets how API calls failing with retryable errors are retried
+func (k *KubernetesFetcher) WithRetryPolicy(policy RetryPolicy) *KubernetesFetcher {
+	k.retryPolicy = policy
+	return k
+}
+
+// WithRateLimit limits API calls made by the fetcher to qps per second with
+// bursts of up to burst calls. The limit is shared by all scans and variable
+// lookups using the fetcher. A qps of zero or less removes the limit.
+func (k *KubernetesFetcher) WithRateLimit(qps float32, burst int) *KubernetesFetcher {
+	if qps <= 0 {
+		k.rateLimiter = nil
+		return k
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	k.rateLimiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+	return k
+}
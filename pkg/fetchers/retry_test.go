@@ -0,0 +1,202 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// flakyClient fails the first calls with the given errors before succeeding.
+// Only Get and List are implemented.
+type flakyClient struct {
+	runtimeclient.Client
+	failures []error
+	calls    int
+}
+
+func (c *flakyClient) next() error {
+	c.calls++
+	if c.calls <= len(c.failures) {
+		return c.failures[c.calls-1]
+	}
+	return nil
+}
+
+func (c *flakyClient) Get(ctx context.Context, key runtimeclient.ObjectKey, obj runtimeclient.Object, opts ...runtimeclient.GetOption) error {
+	if err := c.next(); err != nil {
+		return err
+	}
+	obj.(*unstructured.Unstructured).SetName(key.Name)
+	return nil
+}
+
+func (c *flakyClient) List(ctx context.Context, list runtimeclient.ObjectList, opts ...runtimeclient.ListOption) error {
+	if err := c.next(); err != nil {
+		return err
+	}
+	item := unstructured.Unstructured{}
+	item.SetName("pod-0")
+	list.(*unstructured.UnstructuredList).Items = []unstructured.Unstructured{item}
+	return nil
+}
+
+func TestIsRetryableError(t *testing.T) {
+	gr := schema.GroupResource{Resource: "pods"}
+	tests := []struct {
+		err       error
+		retryable bool
+	}{
+		{apierrors.NewTooManyRequests("slow down", 1), true},
+		{apierrors.NewServiceUnavailable("unavailable"), true},
+		{apierrors.NewInternalError(fmt.Errorf("boom")), true},
+		{apierrors.NewServerTimeout(gr, "list", 1), true},
+		{apierrors.NewGenericServerResponse(502, "get", gr, "", "bad gateway", 0, true), true},
+		{apierrors.NewGenericServerResponse(501, "get", gr, "", "not implemented", 0, true), false},
+		{apierrors.NewNotFound(gr, "web"), false},
+		{apierrors.NewForbidden(gr, "web", fmt.Errorf("denied")), false},
+		{fmt.Errorf("wrapped: %w", apierrors.NewTooManyRequests("slow down", 1)), true},
+		{context.DeadlineExceeded, false},
+		{fmt.Errorf("connection refused"), false},
+		{io.EOF, true},
+		{fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), true},
+		{&url.Error{Op: "Get", URL: "https://api:6443/api/v1/pods", Err: &net.OpError{
+			Op: "read", Net: "tcp", Err: &os.SyscallError{Syscall: "read", Err: syscall.ECONNRESET},
+		}}, true},
+		{&url.Error{Op: "Get", URL: "https://api:6443/api/v1/pods", Err: fmt.Errorf("http2: client connection lost")}, true},
+		{&url.Error{Op: "Get", URL: "https://api:6443/api/v1/pods", Err: &net.DNSError{Err: "i/o timeout", Name: "api", IsTimeout: true}}, true},
+		{&url.Error{Op: "Get", URL: "https://api:6443/api/v1/pods", Err: &net.DNSError{Err: "no such host", Name: "api"}}, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.retryable, IsRetryableError(tt.err), "%v", tt.err)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(0, nil))
+	assert.Equal(t, 400*time.Millisecond, policy.backoff(2, nil))
+	assert.Equal(t, time.Second, policy.backoff(10, nil))
+
+	// Retry-After is honoured up to the maximum backoff
+	assert.Equal(t, time.Second, policy.backoff(0, apierrors.NewTooManyRequests("slow down", 30)))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		delay := policy.backoff(0, nil)
+		assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+		assert.LessOrEqual(t, delay, 150*time.Millisecond)
+	}
+}
+
+func TestKubernetesFetcher_Retries(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	throttled := apierrors.NewTooManyRequests("slow down", 0)
+	inputs := []scanner.Input{scanner.NewKubernetesInput("pods", "", "v1", "pods", "default", "")}
+
+	t.Run("succeeds after retryable errors", func(t *testing.T) {
+		client := &flakyClient{failures: []error{throttled, apierrors.NewServiceUnavailable("unavailable")}}
+		fetcher := NewKubernetesFetcher(client, nil).WithRetryPolicy(policy)
+
+		result, warnings, err := fetcher.FetchInputsWithContext(context.Background(), inputs, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, client.calls)
+		assert.Len(t, result["pods"].(map[string]interface{})["items"], 1)
+		assert.Contains(t, warnings, "input pods: fetched after 2 retries")
+	})
+
+	t.Run("gives up after the maximum retries", func(t *testing.T) {
+		client := &flakyClient{failures: []error{throttled, throttled, throttled, throttled}}
+		fetcher := NewKubernetesFetcher(client, nil).WithRetryPolicy(policy)
+
+		_, _, err := fetcher.FetchInputsWithContext(context.Background(), inputs, nil)
+		require.Error(t, err)
+		assert.Equal(t, 3, client.calls)
+		assert.True(t, apierrors.IsTooManyRequests(err))
+		assert.Contains(t, err.Error(), "after 2 retries")
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		client := &flakyClient{failures: []error{apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", fmt.Errorf("denied"))}}
+		fetcher := NewKubernetesFetcher(client, nil).WithRetryPolicy(policy)
+
+		_, _, err := fetcher.FetchInputsWithContext(context.Background(), inputs, nil)
+		require.Error(t, err)
+		assert.Equal(t, 1, client.calls)
+	})
+
+	t.Run("stops retrying when the context is done", func(t *testing.T) {
+		client := &flakyClient{failures: []error{throttled, throttled}}
+		fetcher := NewKubernetesFetcher(client, nil).WithRetryPolicy(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Hour})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, _, err := fetcher.FetchInputsWithContext(ctx, inputs, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 1, client.calls)
+	})
+}
+
+func TestKubernetesFetcher_RateLimit(t *testing.T) {
+	client := &flakyClient{}
+	fetcher := NewKubernetesFetcher(client, nil).WithRateLimit(50, 1)
+	inputs := []scanner.Input{scanner.NewKubernetesInput("web", "", "v1", "pods", "default", "web")}
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, _, err := fetcher.FetchInputsWithContext(context.Background(), inputs, nil)
+		require.NoError(t, err)
+	}
+
+	// One call is allowed immediately, the other three wait 20ms each
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestKubernetesFetcher_RateLimitDeadline(t *testing.T) {
+	client := &flakyClient{}
+	fetcher := NewKubernetesFetcher(client, nil).WithRateLimit(0.1, 1)
+	inputs := []scanner.Input{scanner.NewKubernetesInput("web", "", "v1", "pods", "default", "web")}
+
+	_, _, err := fetcher.FetchInputsWithContext(context.Background(), inputs, nil)
+	require.NoError(t, err)
+
+	// The next token comes after 10s, past the deadline: the limiter refuses
+	// to wait before the deadline is reached
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, _, err = fetcher.FetchInputsWithContext(ctx, inputs, nil)
+	require.Error(t, err)
+	assert.NoError(t, ctx.Err(), "the deadline has not been reached yet")
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "the refusal is reported as the deadline, which is not cached as a fetch failure")
+	assert.Equal(t, 1, client.calls)
+}
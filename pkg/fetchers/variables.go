@@ -0,0 +1,184 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResolveVariable reads the value of a variable from the field of the
+// Kubernetes object it references. Strings are returned as is, other values
+// as JSON so that typed variables can parse them.
+func (k *KubernetesFetcher) ResolveVariable(ctx context.Context, variable scanner.ObjectReferenceVariable) (string, error) {
+	object, err := k.fetchVariableObject(ctx, variable)
+	if err != nil {
+		return "", err
+	}
+	return variableFieldValue(object, variable)
+}
+
+// variableFieldValue returns the field a variable references in object,
+// encoded as ResolveVariable does
+func variableFieldValue(object map[string]interface{}, variable scanner.ObjectReferenceVariable) (string, error) {
+	value, err := lookupFieldPath(object, variable.FieldPath())
+	if err != nil {
+		return "", err
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode field %s: %w", variable.FieldPath(), err)
+	}
+	return string(encoded), nil
+}
+
+// fetchVariableObject retrieves the object a variable references, from
+// pre-fetched files or the live API
+func (k *KubernetesFetcher) fetchVariableObject(ctx context.Context, variable scanner.ObjectReferenceVariable) (map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	gvk := variable.GroupVersionKind()
+	if gvk.Kind == "" {
+		return nil, fmt.Errorf("variable %s does not set the kind of the object it references", variable.Name())
+	}
+
+	if k.apiResourcePath != "" {
+		data, err := k.fetchFromFile(variableInput(variable))
+		if err != nil {
+			return nil, err
+		}
+		object, ok := data.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected content for %s %s", gvk.Kind, variable.ObjectName())
+		}
+		return object, nil
+	}
+
+	if k.client == nil {
+		return nil, fmt.Errorf("no Kubernetes client available")
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	key := runtimeclient.ObjectKey{Namespace: variable.Namespace(), Name: variable.ObjectName()}
+	if _, err := k.callAPI(ctx, func(ctx context.Context) error { return k.client.Get(ctx, key, obj) }); err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", gvk.Kind, variable.ObjectName(), err)
+	}
+	return obj.Object, nil
+}
+
+// variableInput describes the object a variable references as a Kubernetes
+// input, guessing the resource from the kind
+func variableInput(variable scanner.ObjectReferenceVariable) *scanner.KubernetesInput {
+	gvk := variable.GroupVersionKind()
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+	return &scanner.KubernetesInput{
+		Group:   gvk.Group,
+		Ver:     gvk.Version,
+		ResType: gvr.Resource,
+		Ns:      variable.Namespace(),
+		ResName: variable.ObjectName(),
+	}
+}
+
+// lookupFieldPath returns the value at path in object. Path segments are
+// separated by dots; keys containing dots are written in brackets, as in
+// data['tailoring.yaml'], and list elements by index, as in items[0].
+func lookupFieldPath(object map[string]interface{}, path string) (interface{}, error) {
+	segments, err := parseFieldPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var current interface{} = object
+	for i, segment := range segments {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return nil, fmt.Errorf("field %s not found", formatFieldPath(segments[:i+1]))
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("field %s: invalid list index %q", formatFieldPath(segments[:i+1]), segment)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("field %s is not an object or list", formatFieldPath(segments[:i]))
+		}
+	}
+
+	if current == nil {
+		return nil, fmt.Errorf("field %s is not set", path)
+	}
+	return current, nil
+}
+
+// parseFieldPath splits a field path into its keys
+func parseFieldPath(path string) ([]string, error) {
+	path = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(path), "{"), "}")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil, fmt.Errorf("field path is empty")
+	}
+
+	var segments []string
+	for len(path) > 0 {
+		switch {
+		case path[0] == '[':
+			end := strings.Index(path, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket in field path")
+			}
+			segments = append(segments, strings.Trim(path[1:end], `'"`))
+			path = path[end+1:]
+		case path[0] == '.':
+			path = path[1:]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			segments = append(segments, path[:end])
+			path = path[end:]
+		}
+	}
+	return segments, nil
+}
+
+// formatFieldPath joins keys back into a readable path
+func formatFieldPath(segments []string) string {
+	if len(segments) == 0 {
+		return "."
+	}
+	return strings.Join(segments, ".")
+}
@@ -0,0 +1,124 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var configMapGVK = schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+// writeTailoringConfigMap stores a pre-fetched ConfigMap list for NewKubernetesFileFetcher
+func writeTailoringConfigMap(t *testing.T) string {
+	apiResourcePath := t.TempDir()
+	dir := filepath.Join(apiResourcePath, "namespaces", "compliance")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	list := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "tailoring", "namespace": "compliance"},
+				"data": map[string]interface{}{
+					"maxPods":        "10",
+					"allowed.images": `["ubi9", "ubi8"]`,
+				},
+				"limits": map[string]interface{}{"replicas": 3},
+			},
+		},
+	}
+	content, err := json.Marshal(list)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "configmaps.json"), content, 0644))
+	return apiResourcePath
+}
+
+func TestKubernetesFetcher_ResolveVariable(t *testing.T) {
+	fetcher := NewKubernetesFileFetcher(writeTailoringConfigMap(t))
+
+	tests := []struct {
+		name       string
+		objectName string
+		fieldPath  string
+		expected   string
+		expectErr  bool
+	}{
+		{name: "configmap key", objectName: "tailoring", fieldPath: "data.maxPods", expected: "10"},
+		{name: "bracketed key", objectName: "tailoring", fieldPath: "data['allowed.images']", expected: `["ubi9", "ubi8"]`},
+		{name: "non-string field", objectName: "tailoring", fieldPath: "{.limits}", expected: `{"replicas":3}`},
+		{name: "missing field", objectName: "tailoring", fieldPath: "data.missing", expectErr: true},
+		{name: "missing object", objectName: "other", fieldPath: "data.maxPods", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variable := scanner.NewObjectCelVariable("v", configMapGVK, "compliance", tt.objectName, tt.fieldPath, scanner.VariableTypeString)
+			value, err := fetcher.ResolveVariable(context.Background(), variable.(scanner.ObjectReferenceVariable))
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestParseFieldPath(t *testing.T) {
+	segments, err := parseFieldPath(`.spec.containers[0]["image.name"]`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"spec", "containers", "0", "image.name"}, segments)
+
+	_, err = parseFieldPath("data[key")
+	assert.Error(t, err)
+
+	_, err = parseFieldPath("")
+	assert.Error(t, err)
+}
+
+func TestCompositeFetcher_ScanWithObjectVariables(t *testing.T) {
+	rule, err := scanner.NewRuleBuilder("max-pods", scanner.RuleTypeCEL).
+		WithKubernetesInput("configmaps", "", "v1", "configmaps", "compliance", "").
+		SetCelExpression("configmaps.items.size() <= maxPods && 'ubi9' in allowed").
+		BuildCelRule()
+	require.NoError(t, err)
+
+	composite := NewCompositeFetcherBuilder().WithKubernetesFiles(writeTailoringConfigMap(t)).Build()
+	s := scanner.NewScanner(composite, nil)
+
+	results, err := s.Scan(context.Background(), scanner.ScanConfig{
+		Rules: []scanner.Rule{rule},
+		Variables: []scanner.CelVariable{
+			scanner.NewObjectCelVariable("maxPods", configMapGVK, "compliance", "tailoring", "data.maxPods", scanner.VariableTypeInt),
+			scanner.NewObjectCelVariable("allowed", configMapGVK, "compliance", "tailoring", "data['allowed.images']", scanner.VariableTypeList),
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	assert.Equal(t, scanner.CheckResultPass, results[0].Status, results[0].ErrorMessage)
+	assert.Empty(t, results[0].Warnings)
+}
@@ -0,0 +1,648 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DefaultWatchDebounce is how long a watcher waits for further changes
+// before re-evaluating the affected rules
+const DefaultWatchDebounce = time.Second
+
+// WatchTriggerInitial is the trigger of the evaluation run once all
+// informers have synced
+const WatchTriggerInitial = "initial"
+
+// WatchEvent reports a change in the status of a rule observed in watch mode
+type WatchEvent struct {
+	Index    int                       `json:"index"`              // Position of the rule in ScanConfig.Rules
+	RuleID   string                    `json:"ruleId"`             // Identifier of the rule
+	Previous scanner.CheckResultStatus `json:"previous,omitempty"` // Status before the change, empty for the first evaluation
+	Current  scanner.CheckResultStatus `json:"current"`            // Status after the change
+	Result   scanner.CheckResult       `json:"result"`             // Result of the evaluation that changed the status
+	Trigger  string                    `json:"trigger"`            // Resources whose change caused the evaluation
+	Time     time.Time                 `json:"time"`
+}
+
+// WatchHandler receives status transitions; it is called from a single goroutine
+type WatchHandler func(event WatchEvent)
+
+// ComplianceWatcher continuously evaluates a rule set against the cluster.
+// It runs an informer for every resource used by a Kubernetes input or
+// referenced by a variable and, when a watched resource changes, re-evaluates
+// only the rules reading it. Resources read only in specific namespaces are
+// watched in those namespaces; others are watched across the cluster.
+type ComplianceWatcher struct {
+	dynamicClient   dynamic.Interface
+	discoveryClient discovery.DiscoveryInterface
+	config          *ResourceMappingConfig
+	logger          scanner.Logger
+	resyncPeriod    time.Duration
+	debounce        time.Duration
+
+	fetcher   *informerFetcher
+	composite *CompositeFetcher
+	scanner   *scanner.Scanner
+}
+
+// NewComplianceWatcher creates a watcher reading resources through
+// dynamicClient. The discovery client is optional and only used to resolve
+// kinds and scopes.
+func NewComplianceWatcher(dynamicClient dynamic.Interface, discoveryClient discovery.DiscoveryInterface, logger scanner.Logger) *ComplianceWatcher {
+	if logger == nil {
+		logger = scanner.DefaultLogger{}
+	}
+
+	w := &ComplianceWatcher{
+		dynamicClient:   dynamicClient,
+		discoveryClient: discoveryClient,
+		config:          DefaultResourceMappingConfig(),
+		logger:          logger,
+		debounce:        DefaultWatchDebounce,
+	}
+	w.fetcher = &informerFetcher{watcher: w, stores: make(map[watchKey]cache.Store)}
+	w.composite = NewCompositeFetcher()
+	w.composite.RegisterCustomFetcher(scanner.InputTypeKubernetes, w.fetcher)
+	w.scanner = scanner.NewScanner(w.composite, logger).WithVariableResolver(w.fetcher)
+	return w
+}
+
+// WithConfig allows customization of the resource mapping configuration
+func (w *ComplianceWatcher) WithConfig(config *ResourceMappingConfig) *ComplianceWatcher {
+	w.config = config
+	return w
+}
+
+// WithResyncPeriod sets how often informers replay their cache. Replayed
+// objects that did not change do not trigger evaluations. Zero disables resyncs.
+func (w *ComplianceWatcher) WithResyncPeriod(period time.Duration) *ComplianceWatcher {
+	w.resyncPeriod = period
+	return w
+}
+
+// WithDebounce sets how long to wait for further changes before re-evaluating
+func (w *ComplianceWatcher) WithDebounce(debounce time.Duration) *ComplianceWatcher {
+	w.debounce = debounce
+	return w
+}
+
+// Scanner returns the scanner evaluating the rules, for further configuration
+func (w *ComplianceWatcher) Scanner() *scanner.Scanner {
+	return w.scanner
+}
+
+// Fetcher returns the fetcher used by the scanner. Kubernetes inputs and
+// variables referencing objects are read from the informer caches; fetchers
+// for other input types can be set on it.
+func (w *ComplianceWatcher) Fetcher() *CompositeFetcher {
+	return w.composite
+}
+
+// Watch starts informers for the resources used by config.Rules and
+// config.Variables, evaluates every rule once they have synced and then
+// re-evaluates the rules affected by each change. handler receives every
+// status transition, including the first status of each rule. Watch blocks
+// until ctx is done and returns its error.
+func (w *ComplianceWatcher) Watch(ctx context.Context, config scanner.ScanConfig, handler WatchHandler) error {
+	watched := w.watchedResources(config)
+	changes := newResourceChanges()
+
+	// Stopping the informers also aborts their in-flight list and watch calls
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	informerCtx, stopInformers := context.WithCancel(ctx)
+	defer stopInformers()
+
+	informers := make(map[watchKey]cache.SharedIndexInformer)
+	for _, key := range watched.keys() {
+		// Informers for resources that are not served would never sync
+		if _, err := w.resourceClient(key).List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+			if !scanner.IsMissingInputError(err) {
+				return fmt.Errorf("failed to list %s: %w", key, err)
+			}
+			w.logger.Warn("Resource %s is not served; inputs reading it are reported as not found", key)
+			w.fetcher.setStore(key, nil)
+			continue
+		}
+
+		informer := w.newInformer(informerCtx, key)
+		if _, err := informer.AddEventHandler(changes.handlerFor(key.gvr)); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", key, err)
+		}
+		informers[key] = informer
+		w.fetcher.setStore(key, informer.GetStore())
+	}
+
+	for key, informer := range informers {
+		wg.Add(1)
+		go func(informer cache.SharedIndexInformer) {
+			defer wg.Done()
+			informer.Run(informerCtx.Done())
+		}(informer)
+		w.logger.Info("Watching %s for %d rules", key, len(watched.rules[key.gvr]))
+	}
+
+	for key, informer := range informers {
+		if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return fmt.Errorf("failed to sync informer for %s", key)
+		}
+	}
+
+	// Objects listed while syncing are covered by the initial evaluation
+	changes.take()
+
+	statuses := make(map[int]scanner.CheckResultStatus, len(config.Rules))
+	all := make([]int, len(config.Rules))
+	for i := range all {
+		all[i] = i
+	}
+	w.evaluate(ctx, config, all, WatchTriggerInitial, statuses, handler)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changes.notify:
+		}
+
+		// Let bursts of changes settle before evaluating
+		if w.debounce > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(w.debounce):
+			}
+		}
+
+		changed := changes.take()
+		if len(changed) == 0 {
+			continue
+		}
+		indexes, trigger := affectedRules(watched.rules, changed)
+		w.logger.Debug("Changes to %s affect %d rules", trigger, len(indexes))
+		w.evaluate(ctx, config, indexes, trigger, statuses, handler)
+	}
+}
+
+// watchKey identifies an informer: a resource watched in a namespace, or
+// across the cluster when namespace is empty
+type watchKey struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+func (k watchKey) String() string {
+	if k.namespace == "" {
+		return resourceName(k.gvr)
+	}
+	return resourceName(k.gvr) + " in namespace " + k.namespace
+}
+
+// watchedResources maps every watched resource to the indexes of the rules
+// reading it and to the namespaces it is read in, "" standing for the whole
+// cluster
+type watchedResources struct {
+	rules      map[schema.GroupVersionResource][]int
+	namespaces map[schema.GroupVersionResource]map[string]bool
+}
+
+// add records that the rules at indexes read gvr in namespace
+func (r *watchedResources) add(gvr schema.GroupVersionResource, namespace string, indexes ...int) {
+	if r.namespaces[gvr] == nil {
+		r.namespaces[gvr] = make(map[string]bool)
+	}
+	r.namespaces[gvr][namespace] = true
+	for _, index := range indexes {
+		known := false
+		for _, existing := range r.rules[gvr] {
+			known = known || existing == index
+		}
+		if !known {
+			r.rules[gvr] = append(r.rules[gvr], index)
+		}
+	}
+}
+
+// keys returns the informers to run: one across the cluster for resources
+// read in every namespace or cluster-scoped, otherwise one per namespace
+func (r *watchedResources) keys() []watchKey {
+	var keys []watchKey
+	for gvr, namespaces := range r.namespaces {
+		if namespaces[""] {
+			keys = append(keys, watchKey{gvr: gvr})
+			continue
+		}
+		for namespace := range namespaces {
+			keys = append(keys, watchKey{gvr: gvr, namespace: namespace})
+		}
+	}
+	return keys
+}
+
+// watchedResources collects the resources read by the Kubernetes inputs of
+// rules and by variables referencing objects. A change to the object of a
+// variable affects the rules mentioning the variable. Subresources, such as
+// pods/log, have no informers and are left out with a warning; inputs
+// reading them fail.
+func (w *ComplianceWatcher) watchedResources(config scanner.ScanConfig) *watchedResources {
+	watched := &watchedResources{
+		rules:      make(map[schema.GroupVersionResource][]int),
+		namespaces: make(map[schema.GroupVersionResource]map[string]bool),
+	}
+	skipped := make(map[schema.GroupVersionResource]bool)
+	for i, rule := range config.Rules {
+		for _, input := range rule.Inputs() {
+			if input.Type() != scanner.InputTypeKubernetes {
+				continue
+			}
+			spec, ok := input.Spec().(scanner.KubernetesInputSpec)
+			if !ok {
+				continue
+			}
+			gvr := watchedResource(spec)
+			if isSubresource(gvr) {
+				if !skipped[gvr] {
+					skipped[gvr] = true
+					w.logger.Warn("Subresource %s cannot be watched; inputs reading it are reported as errors", resourceName(gvr))
+				}
+				continue
+			}
+			watched.add(gvr, w.namespaceOf(spec), i)
+		}
+	}
+
+	for _, variable := range config.Variables {
+		ref, ok := variable.(scanner.ObjectReferenceVariable)
+		if !ok || ref.ObjectName() == "" || ref.GroupVersionKind().Kind == "" {
+			continue
+		}
+		spec := variableInput(ref)
+		var indexes []int
+		for i, rule := range config.Rules {
+			if scanner.RuleMentionsVariable(rule, ref.Name()) {
+				indexes = append(indexes, i)
+			}
+		}
+		watched.add(watchedResource(spec), w.namespaceOf(spec), indexes...)
+	}
+
+	for gvr := range watched.rules {
+		sort.Ints(watched.rules[gvr])
+	}
+	return watched
+}
+
+// namespaceOf returns the namespace an input reads, or "" when it reads
+// every namespace or a cluster-scoped resource
+func (w *ComplianceWatcher) namespaceOf(spec scanner.KubernetesInputSpec) string {
+	if spec.Namespace() != "" && IsNamespacedWithConfig(spec, w.discoveryClient, w.config) {
+		return spec.Namespace()
+	}
+	return ""
+}
+
+// resourceClient returns the client listing and watching the objects of key
+func (w *ComplianceWatcher) resourceClient(key watchKey) dynamic.ResourceInterface {
+	if key.namespace == "" {
+		return w.dynamicClient.Resource(key.gvr)
+	}
+	return w.dynamicClient.Resource(key.gvr).Namespace(key.namespace)
+}
+
+// newInformer creates an informer for a resource in the namespace of key, or
+// across the cluster; inputs limited to a name are filtered when read. List
+// and watch calls are made under ctx, so cancelling it aborts them.
+func (w *ComplianceWatcher) newInformer(ctx context.Context, key watchKey) cache.SharedIndexInformer {
+	resource := w.resourceClient(key)
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return resource.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return resource.Watch(ctx, options)
+		},
+	}
+	return cache.NewSharedIndexInformer(listWatch, &unstructured.Unstructured{}, w.resyncPeriod, cache.Indexers{})
+}
+
+// evaluate scans the rules at indexes and reports those whose status changed
+func (w *ComplianceWatcher) evaluate(ctx context.Context, config scanner.ScanConfig, indexes []int, trigger string, statuses map[int]scanner.CheckResultStatus, handler WatchHandler) {
+	subset := config
+	subset.Rules = make([]scanner.Rule, len(indexes))
+	for i, index := range indexes {
+		subset.Rules[i] = config.Rules[index]
+	}
+
+	results, err := w.scanner.Scan(ctx, subset)
+	if err != nil {
+		// The watch is stopping; partial results are not reported
+		return
+	}
+
+	now := time.Now()
+	for i, result := range results {
+		index := indexes[i]
+		previous, seen := statuses[index]
+		if seen && previous == result.Status {
+			continue
+		}
+		statuses[index] = result.Status
+		handler(WatchEvent{
+			Index:    index,
+			RuleID:   result.ID,
+			Previous: previous,
+			Current:  result.Status,
+			Result:   result,
+			Trigger:  trigger,
+			Time:     now,
+		})
+	}
+}
+
+// affectedRules returns the sorted indexes of the rules reading any of the
+// changed resources, and a description of the change
+func affectedRules(rulesByResource map[schema.GroupVersionResource][]int, changed []schema.GroupVersionResource) ([]int, string) {
+	seen := make(map[int]bool)
+	var indexes []int
+	names := make([]string, 0, len(changed))
+	for _, gvr := range changed {
+		names = append(names, resourceName(gvr))
+		for _, index := range rulesByResource[gvr] {
+			if !seen[index] {
+				seen[index] = true
+				indexes = append(indexes, index)
+			}
+		}
+	}
+	sort.Ints(indexes)
+	sort.Strings(names)
+	return indexes, strings.Join(names, ", ")
+}
+
+// watchedResource returns the resource an input is read from
+func watchedResource(spec scanner.KubernetesInputSpec) schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    spec.ApiGroup(),
+		Version:  spec.Version(),
+		Resource: strings.ToLower(spec.ResourceType()),
+	}
+}
+
+// isSubresource reports whether gvr names a subresource, such as pods/log
+func isSubresource(gvr schema.GroupVersionResource) bool {
+	return strings.Contains(gvr.Resource, "/")
+}
+
+// resourceName renders a resource as group/version/resource, or
+// version/resource for the core group
+func resourceName(gvr schema.GroupVersionResource) string {
+	return gvr.GroupVersion().String() + "/" + gvr.Resource
+}
+
+// resourceChanges collects the resources changed since the last evaluation
+type resourceChanges struct {
+	mu      sync.Mutex
+	changed map[schema.GroupVersionResource]bool
+	notify  chan struct{}
+}
+
+func newResourceChanges() *resourceChanges {
+	return &resourceChanges{
+		changed: make(map[schema.GroupVersionResource]bool),
+		notify:  make(chan struct{}, 1),
+	}
+}
+
+// mark records a change without blocking the informer delivering it
+func (c *resourceChanges) mark(gvr schema.GroupVersionResource) {
+	c.mu.Lock()
+	c.changed[gvr] = true
+	c.mu.Unlock()
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// take returns and clears the changed resources
+func (c *resourceChanges) take() []schema.GroupVersionResource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changed := make([]schema.GroupVersionResource, 0, len(c.changed))
+	for gvr := range c.changed {
+		changed = append(changed, gvr)
+	}
+	c.changed = make(map[schema.GroupVersionResource]bool)
+	return changed
+}
+
+// handlerFor returns informer callbacks marking gvr as changed. Resyncs
+// replaying an unchanged object are ignored.
+func (c *resourceChanges) handlerFor(gvr schema.GroupVersionResource) cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.mark(gvr) },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldMeta, oldErr := meta.Accessor(oldObj)
+			newMeta, newErr := meta.Accessor(newObj)
+			if oldErr == nil && newErr == nil && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion() {
+				return
+			}
+			c.mark(gvr)
+		},
+		DeleteFunc: func(obj interface{}) { c.mark(gvr) },
+	}
+}
+
+// informerFetcher serves Kubernetes inputs and variables referencing objects
+// from informer caches in the same shape as KubernetesFetcher
+type informerFetcher struct {
+	watcher *ComplianceWatcher
+
+	mu     sync.RWMutex
+	stores map[watchKey]cache.Store
+}
+
+// setStore registers the cache holding the objects of a resource; a nil
+// store marks a resource that is not served
+func (f *informerFetcher) setStore(key watchKey, store cache.Store) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stores[key] = store
+}
+
+// store returns the cache serving a resource in namespace: the cluster-wide
+// cache of the resource when there is one, or the cache of the namespace
+func (f *informerFetcher) store(gvr schema.GroupVersionResource, namespace string) (cache.Store, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if store, ok := f.stores[watchKey{gvr: gvr}]; ok {
+		return store, true
+	}
+	if namespace == "" {
+		return nil, false
+	}
+	store, ok := f.stores[watchKey{gvr: gvr, namespace: namespace}]
+	return store, ok
+}
+
+// ResolveVariable reads variables referencing Kubernetes objects from the
+// informer caches
+func (f *informerFetcher) ResolveVariable(ctx context.Context, variable scanner.ObjectReferenceVariable) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	gvk := variable.GroupVersionKind()
+	if gvk.Kind == "" {
+		return "", fmt.Errorf("variable %s does not set the kind of the object it references", variable.Name())
+	}
+
+	data, err := f.read(variableInput(variable))
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s %s: %w", gvk.Kind, variable.ObjectName(), err)
+	}
+	object, ok := data.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("unexpected content for %s %s", gvk.Kind, variable.ObjectName())
+	}
+	return variableFieldValue(object, variable)
+}
+
+// SupportsInputType returns true for Kubernetes input types
+func (f *informerFetcher) SupportsInputType(inputType scanner.InputType) bool {
+	return inputType == scanner.InputTypeKubernetes
+}
+
+// FetchInputs reads Kubernetes inputs from the informer caches
+func (f *informerFetcher) FetchInputs(inputs []scanner.Input, variables []scanner.CelVariable) (map[string]interface{}, error) {
+	result, _, err := f.FetchInputsWithContext(context.Background(), inputs, variables)
+	return result, err
+}
+
+// FetchInputsWithContext reads Kubernetes inputs from the informer caches
+func (f *informerFetcher) FetchInputsWithContext(ctx context.Context, inputs []scanner.Input, variables []scanner.CelVariable) (map[string]interface{}, []string, error) {
+	// Scope and warnings are resolved like for a live fetch
+	scope := &KubernetesFetcher{discoveryClient: f.watcher.discoveryClient, config: f.watcher.config}
+	result := make(map[string]interface{})
+	var warnings []string
+
+	for _, input := range inputs {
+		if input.Type() != scanner.InputTypeKubernetes {
+			continue
+		}
+		spec, ok := input.Spec().(scanner.KubernetesInputSpec)
+		if !ok {
+			return nil, warnings, fmt.Errorf("invalid Kubernetes input spec for input %s", input.Name())
+		}
+
+		data, err := f.read(spec)
+		if err != nil && scanner.IsOptionalInput(input) && scanner.IsMissingInputError(err) {
+			warnings = append(warnings, scanner.AbsentInputWarning(input, err))
+			result[input.Name()] = scanner.AbsentInputValue(input)
+			continue
+		}
+		if err != nil {
+			return nil, warnings, fmt.Errorf("failed to fetch Kubernetes resource for input %s: %w", input.Name(), err)
+		}
+
+		warnings = append(warnings, scope.resourceWarnings(input.Name(), spec, data)...)
+		result[input.Name()] = data
+	}
+
+	return result, warnings, nil
+}
+
+// read returns a named object or the list of objects matching spec
+func (f *informerFetcher) read(spec scanner.KubernetesInputSpec) (interface{}, error) {
+	gvr := watchedResource(spec)
+	if isSubresource(gvr) {
+		return nil, fmt.Errorf("subresource %s cannot be read from informer caches", resourceName(gvr))
+	}
+	namespace := f.watcher.namespaceOf(spec)
+	store, ok := f.store(gvr, namespace)
+	if !ok {
+		if namespace != "" {
+			return nil, fmt.Errorf("resource %s is not watched in namespace %s", resourceName(gvr), namespace)
+		}
+		return nil, fmt.Errorf("resource %s is not watched", resourceName(gvr))
+	}
+	if store == nil {
+		return nil, apierrors.NewNotFound(gvr.GroupResource(), spec.Name())
+	}
+
+	if spec.Name() != "" {
+		key := spec.Name()
+		if namespace != "" {
+			key = namespace + "/" + key
+		}
+		obj, exists, err := store.GetByKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, apierrors.NewNotFound(gvr.GroupResource(), spec.Name())
+		}
+		return obj.(*unstructured.Unstructured).DeepCopy().Object, nil
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, obj := range store.List() {
+		object := obj.(*unstructured.Unstructured)
+		if namespace == "" || object.GetNamespace() == namespace {
+			objects = append(objects, object)
+		}
+	}
+	// Caches are unordered; sort so that results and findings are stable
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].GetNamespace() != objects[j].GetNamespace() {
+			return objects[i].GetNamespace() < objects[j].GetNamespace()
+		}
+		return objects[i].GetName() < objects[j].GetName()
+	})
+
+	gvk := GetGVKWithConfig(spec, f.watcher.config, f.watcher.discoveryClient)
+	items := make([]interface{}, len(objects))
+	for i, object := range objects {
+		items[i] = object.DeepCopy().Object
+	}
+	return map[string]interface{}{
+		"apiVersion": gvk.GroupVersion().String(),
+		"kind":       gvk.Kind + "List",
+		"items":      items,
+	}, nil
+}
@@ -0,0 +1,283 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetchers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ComplianceAsCode/compliance-sdk/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var (
+	podsGVR       = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	policiesGVR   = schema.GroupVersionResource{Group: "policy.example.com", Version: "v1", Resource: "policies"}
+)
+
+func newUnstructured(apiVersion, kind, namespace, name string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	return obj
+}
+
+func TestComplianceWatcher_Watch(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			podsGVR:       "PodList",
+			configMapsGVR: "ConfigMapList",
+			policiesGVR:   "PolicyList",
+		},
+		newUnstructured("v1", "Pod", "default", "web", map[string]string{"app": "web"}),
+		newUnstructured("v1", "Pod", "other", "unlabelled", nil),
+	)
+	// The policy CRD is not installed
+	client.PrependReactor("list", "policies", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(policiesGVR.GroupResource(), "")
+	})
+
+	podsLabelled, err := scanner.NewRuleBuilder("pods-labelled", scanner.RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "default", "").
+		SetCelExpression("pods.items.all(p, has(p.metadata.labels) && 'app' in p.metadata.labels)").
+		BuildCelRule()
+	require.NoError(t, err)
+	settingsPresent, err := scanner.NewRuleBuilder("settings-present", scanner.RuleTypeCEL).
+		WithKubernetesInput("settings", "", "v1", "configmaps", "default", "settings").
+		WithKubernetesInput("policies", "policy.example.com", "v1", "policies", "", "").
+		MarkInputOptional("settings").
+		MarkInputOptional("policies").
+		SetCelExpression("settings != null && policies.items.size() == 0").
+		BuildCelRule()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan WatchEvent, 10)
+	done := make(chan error, 1)
+	watcher := NewComplianceWatcher(client, nil, nil).WithDebounce(10 * time.Millisecond)
+	go func() {
+		done <- watcher.Watch(ctx, scanner.ScanConfig{Rules: []scanner.Rule{podsLabelled, settingsPresent}}, func(event WatchEvent) {
+			events <- event
+		})
+	}()
+
+	next := func() WatchEvent {
+		t.Helper()
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(10 * time.Second):
+			t.Fatal("Timed out waiting for a watch event")
+			return WatchEvent{}
+		}
+	}
+
+	initial := map[string]WatchEvent{}
+	for i := 0; i < 2; i++ {
+		event := next()
+		initial[event.RuleID] = event
+	}
+	assert.Equal(t, scanner.CheckResultPass, initial["pods-labelled"].Current)
+	assert.Equal(t, scanner.CheckResultFail, initial["settings-present"].Current)
+	assert.Empty(t, initial["settings-present"].Previous)
+	assert.Equal(t, WatchTriggerInitial, initial["settings-present"].Trigger)
+
+	// Changes made before the informers watch would be missed
+	require.Eventually(t, func() bool {
+		watches := 0
+		for _, action := range client.Actions() {
+			if action.GetVerb() == "watch" {
+				watches++
+			}
+		}
+		return watches == 2
+	}, 10*time.Second, 10*time.Millisecond)
+
+	pods := client.Resource(podsGVR)
+	_, err = pods.Namespace("default").Create(ctx, newUnstructured("v1", "Pod", "default", "db", nil), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	event := next()
+	assert.Equal(t, "pods-labelled", event.RuleID)
+	assert.Equal(t, scanner.CheckResultPass, event.Previous)
+	assert.Equal(t, scanner.CheckResultFail, event.Current)
+	assert.Equal(t, "v1/pods", event.Trigger)
+
+	// Changes outside the namespace of the input do not change its status
+	_, err = pods.Namespace("other").Create(ctx, newUnstructured("v1", "Pod", "other", "batch", nil), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	_, err = client.Resource(configMapsGVR).Namespace("default").Create(ctx, newUnstructured("v1", "ConfigMap", "default", "settings", nil), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	event = next()
+	assert.Equal(t, "settings-present", event.RuleID)
+	assert.Equal(t, scanner.CheckResultFail, event.Previous)
+	assert.Equal(t, scanner.CheckResultPass, event.Current)
+	assert.Contains(t, event.Trigger, "v1/configmaps")
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(10 * time.Second):
+		t.Fatal("Watch did not stop after cancellation")
+	}
+	assert.Empty(t, events)
+}
+
+func TestComplianceWatcher_Subresources(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{podsGVR: "PodList"},
+		newUnstructured("v1", "Pod", "default", "web", map[string]string{"app": "web"}),
+	)
+
+	podsLabelled, err := scanner.NewRuleBuilder("pods-labelled", scanner.RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "default", "").
+		SetCelExpression("pods.items.all(p, has(p.metadata.labels))").
+		BuildCelRule()
+	require.NoError(t, err)
+	webLogs, err := scanner.NewRuleBuilder("web-logs", scanner.RuleTypeCEL).
+		WithKubernetesInput("logs", "", "v1", "pods/log", "default", "web").
+		SetCelExpression("logs != null").
+		BuildCelRule()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan WatchEvent, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- NewComplianceWatcher(client, nil, nil).Watch(ctx, scanner.ScanConfig{Rules: []scanner.Rule{podsLabelled, webLogs}}, func(event WatchEvent) {
+			events <- event
+		})
+	}()
+
+	initial := map[string]WatchEvent{}
+	for len(initial) < 2 {
+		select {
+		case event := <-events:
+			initial[event.RuleID] = event
+		case err := <-done:
+			t.Fatalf("Watch stopped: %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("Timed out waiting for a watch event")
+		}
+	}
+	assert.Equal(t, scanner.CheckResultPass, initial["pods-labelled"].Current, "rules reading watchable resources are still evaluated")
+	assert.Equal(t, scanner.CheckResultError, initial["web-logs"].Current)
+	assert.Contains(t, initial["web-logs"].Result.ErrorMessage, "subresource v1/pods/log cannot be read from informer caches")
+
+	for _, action := range client.Actions() {
+		assert.NotEqual(t, "pods/log", action.GetResource().Resource, "subresources are not listed or watched")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestComplianceWatcher_Variables(t *testing.T) {
+	tailoring := newUnstructured("v1", "ConfigMap", "compliance", "tailoring", nil)
+	require.NoError(t, unstructured.SetNestedField(tailoring.Object, "1", "data", "minPods"))
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{podsGVR: "PodList", configMapsGVR: "ConfigMapList"},
+		newUnstructured("v1", "Pod", "default", "web", nil),
+		tailoring,
+	)
+
+	enoughPods, err := scanner.NewRuleBuilder("enough-pods", scanner.RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "default", "").
+		SetCelExpression("pods.items.size() >= minPods").
+		BuildCelRule()
+	require.NoError(t, err)
+	config := scanner.ScanConfig{
+		Rules: []scanner.Rule{enoughPods},
+		Variables: []scanner.CelVariable{
+			scanner.NewObjectCelVariable("minPods", configMapGVK, "compliance", "tailoring", "data.minPods", scanner.VariableTypeInt),
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan WatchEvent, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- NewComplianceWatcher(client, nil, nil).WithDebounce(10*time.Millisecond).Watch(ctx, config, func(event WatchEvent) {
+			events <- event
+		})
+	}()
+
+	next := func() WatchEvent {
+		t.Helper()
+		select {
+		case event := <-events:
+			return event
+		case err := <-done:
+			t.Fatalf("Watch stopped: %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("Timed out waiting for a watch event")
+		}
+		return WatchEvent{}
+	}
+
+	event := next()
+	assert.Equal(t, scanner.CheckResultPass, event.Current, "the variable is resolved from the informer cache")
+	assert.Empty(t, event.Result.Warnings)
+
+	require.Eventually(t, func() bool {
+		watches := 0
+		for _, action := range client.Actions() {
+			if action.GetVerb() == "watch" {
+				watches++
+			}
+		}
+		return watches == 2
+	}, 10*time.Second, 10*time.Millisecond)
+	for _, action := range client.Actions() {
+		expected := map[string]string{"pods": "default", "configmaps": "compliance"}[action.GetResource().Resource]
+		assert.Equal(t, expected, action.GetNamespace(), "%s of %s is limited to the namespace read", action.GetVerb(), action.GetResource().Resource)
+	}
+
+	require.NoError(t, unstructured.SetNestedField(tailoring.Object, "2", "data", "minPods"))
+	tailoring.SetResourceVersion("2")
+	_, err = client.Resource(configMapsGVR).Namespace("compliance").Update(ctx, tailoring, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	event = next()
+	assert.Equal(t, "enough-pods", event.RuleID)
+	assert.Equal(t, scanner.CheckResultFail, event.Current, "a change to the referenced object re-evaluates the rule")
+	assert.Equal(t, "v1/configmaps", event.Trigger)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"errors"
+)
+
+// NewContextInputFetcher adapts an InputFetcher to the ContextInputFetcher
+// interface. Fetchers that already implement it are returned unchanged.
+// Other fetchers cannot be interrupted, so the adapter stops waiting for them
+// once ctx is done and returns the context error instead.
+func NewContextInputFetcher(fetcher InputFetcher) ContextInputFetcher {
+	if contextFetcher, ok := fetcher.(ContextInputFetcher); ok {
+		return contextFetcher
+	}
+	return &contextFetcherAdapter{InputFetcher: fetcher}
+}
+
+// contextFetcherAdapter runs a context-unaware InputFetcher under a context
+type contextFetcherAdapter struct {
+	InputFetcher
+}
+
+// fetchResult carries the outcome of a fetch run in the background
+type fetchResult struct {
+	data map[string]interface{}
+	err  error
+}
+
+// FetchInputsWithContext runs FetchInputs and returns early if ctx is done
+func (a *contextFetcherAdapter) FetchInputsWithContext(ctx context.Context, inputs []Input, variables []CelVariable) (map[string]interface{}, []string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	done := make(chan fetchResult, 1)
+	go func() {
+		data, err := a.FetchInputs(inputs, variables)
+		done <- fetchResult{data: data, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.data, nil, result.err
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+// isContextError reports whether err was caused by a cancelled or expired context
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
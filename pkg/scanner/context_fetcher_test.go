@@ -0,0 +1,180 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingInputFetcher is an InputFetcher without context support that
+// blocks until released
+type blockingInputFetcher struct {
+	release chan struct{}
+}
+
+func (f *blockingInputFetcher) FetchInputs(inputs []Input, variables []CelVariable) (map[string]interface{}, error) {
+	<-f.release
+	return map[string]interface{}{"pods": []interface{}{}}, nil
+}
+
+func (f *blockingInputFetcher) SupportsInputType(inputType InputType) bool {
+	return true
+}
+
+func TestNewContextInputFetcher(t *testing.T) {
+	fetcher := &blockingInputFetcher{release: make(chan struct{})}
+	defer close(fetcher.release)
+
+	adapted := NewContextInputFetcher(fetcher)
+	if NewContextInputFetcher(adapted) != adapted {
+		t.Error("Expected a ContextInputFetcher to be returned unchanged")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := adapted.FetchInputsWithContext(ctx, []Input{NewKubernetesInput("pods", "", "v1", "pods", "", "")}, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the adapter to return at the deadline, took %s", elapsed)
+	}
+}
+
+func buildTimeoutRules(t *testing.T, count int) []Rule {
+	var rules []Rule
+	for i := 0; i < count; i++ {
+		rule, err := NewRuleBuilder(fmt.Sprintf("rule-%d", i), RuleTypeCEL).
+			WithKubernetesInput("pods", "", "v1", "pods", fmt.Sprintf("namespace-%d", i), "").
+			SetCelExpression("pods.items.size() == 0").
+			BuildCelRule()
+		if err != nil {
+			t.Fatalf("Failed to build rule: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func TestScanner_RuleTimeout(t *testing.T) {
+	fetcher := &countingFetcher{
+		delay: 5 * time.Second,
+		data:  map[string]interface{}{"pods": map[string]interface{}{"items": []interface{}{}}},
+	}
+	scanner := NewScanner(fetcher, &TestLogger{t: t})
+
+	results, err := scanner.Scan(context.Background(), ScanConfig{
+		Rules:       buildTimeoutRules(t, 2),
+		RuleTimeout: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, result := range results {
+		if result.Status != CheckResultError {
+			t.Errorf("Rule %s: expected ERROR, got %s", result.ID, result.Status)
+		}
+		if !strings.Contains(result.ErrorMessage, "rule timed out after 20ms while fetching inputs") {
+			t.Errorf("Rule %s: unexpected error message %q", result.ID, result.ErrorMessage)
+		}
+	}
+}
+
+func TestScanner_ScanTimeout(t *testing.T) {
+	fetcher := &countingFetcher{
+		delay: 5 * time.Second,
+		data:  map[string]interface{}{"pods": map[string]interface{}{"items": []interface{}{}}},
+	}
+	scanner := NewScanner(fetcher, &TestLogger{t: t})
+
+	results, err := scanner.Scan(context.Background(), ScanConfig{
+		Rules:       buildTimeoutRules(t, 3),
+		ScanTimeout: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Scan timeouts should be reported per rule, got %v", err)
+	}
+
+	if !strings.Contains(results[0].ErrorMessage, "Rule not completed: scan timed out after 20ms") {
+		t.Errorf("Unexpected error message for the running rule: %q", results[0].ErrorMessage)
+	}
+	for _, result := range results[1:] {
+		if result.Status != CheckResultError || result.ErrorMessage != "Rule not started: scan timed out after 20ms" {
+			t.Errorf("Rule %s: unexpected result %s %q", result.ID, result.Status, result.ErrorMessage)
+		}
+	}
+	if calls := fetcher.calls.Load(); calls != 1 {
+		t.Errorf("Expected 1 fetch before the deadline, got %d", calls)
+	}
+}
+
+func TestScanner_ScanCancelled(t *testing.T) {
+	fetcher := &countingFetcher{data: map[string]interface{}{}}
+	scanner := NewScanner(fetcher, &TestLogger{t: t})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := scanner.Scan(ctx, ScanConfig{Rules: buildTimeoutRules(t, 2)})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.Status != CheckResultError || result.ErrorMessage != "Rule not started: scan was cancelled" {
+			t.Errorf("Rule %s: unexpected result %s %q", result.ID, result.Status, result.ErrorMessage)
+		}
+	}
+	if calls := fetcher.calls.Load(); calls != 0 {
+		t.Errorf("Expected no fetches after cancellation, got %d", calls)
+	}
+}
+
+func TestInputSnapshot_DoesNotCacheInterruptedFetch(t *testing.T) {
+	var calls int
+	snapshot := newInputSnapshot(func(ctx context.Context, rule Rule, input Input) (interface{}, []string, error) {
+		calls++
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		return "data", nil, nil
+	})
+	input := NewKubernetesInput("pods", "", "v1", "pods", "default", "")
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := snapshot.Get(cancelled, nil, input); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	data, _, err := snapshot.Get(context.Background(), nil, input)
+	if err != nil || data != "data" {
+		t.Fatalf("Expected the input to be fetched again, got %v, %v", data, err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 fetches, got %d", calls)
+	}
+}
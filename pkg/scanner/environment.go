@@ -0,0 +1,106 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sdkModulePath is the module path of the SDK in build information
+const sdkModulePath = "github.com/ComplianceAsCode/compliance-sdk"
+
+// ClusterInfo describes the cluster a fetcher reads from
+type ClusterInfo struct {
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	Platform          string `json:"platform,omitempty"`
+}
+
+// ClusterInfoProvider is implemented by fetchers able to describe the cluster
+// they read from. The scanner records the description in the environment of
+// every scan; a zero ClusterInfo means there is no cluster to describe.
+type ClusterInfoProvider interface {
+	ClusterInfo(ctx context.Context) (ClusterInfo, error)
+}
+
+// SDKVersion returns the version of the SDK module the running binary was
+// built with, "(devel)" when built from the SDK source tree, or "unknown"
+// when the binary carries no build information
+func SDKVersion() string {
+	return sdkVersion()
+}
+
+// sdkVersion reads the build information once
+var sdkVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Path == sdkModulePath {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != sdkModulePath {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+	return "unknown"
+})
+
+// newScanEnvironment describes a scan starting now. Failing to detect the
+// cluster is logged and leaves its fields empty.
+func (s *Scanner) newScanEnvironment(ctx context.Context, config ScanConfig) ScanEnvironment {
+	environment := ScanEnvironment{
+		ScanID:     config.ScanID,
+		StartTime:  time.Now().UTC(),
+		SDKVersion: SDKVersion(),
+	}
+	if environment.ScanID == "" {
+		environment.ScanID = uuid.NewString()
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		environment.Hostname = hostname
+	}
+
+	if provider, ok := s.resourceFetcher.(ClusterInfoProvider); ok {
+		info, err := provider.ClusterInfo(ctx)
+		if err != nil {
+			s.logger.Warn("Cannot detect the cluster version and platform: %v", err)
+		}
+		environment.KubernetesVersion = info.KubernetesVersion
+		environment.Platform = info.Platform
+	}
+	return environment
+}
+
+// finish records the scan environment on a result, and the outcome of the
+// rule in its statistics, and delivers the result
+func (r *scanRun) finish(index int, rule Rule, result CheckResult) {
+	result.Metadata.Environment = r.environment
+	r.rules[index].ID = rule.Identifier()
+	r.rules[index].Status = result.Status
+	r.rules[index].Cached = result.Cached
+	r.sink.finish(index, rule, result)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// clusterFetcher is a countingFetcher describing the cluster it reads from
+type clusterFetcher struct {
+	countingFetcher
+	info ClusterInfo
+	err  error
+}
+
+func (f *clusterFetcher) ClusterInfo(ctx context.Context) (ClusterInfo, error) {
+	return f.info, f.err
+}
+
+func environmentRules(t *testing.T) []Rule {
+	t.Helper()
+	var rules []Rule
+	for _, id := range []string{"first", "second"} {
+		rule, err := NewRuleBuilder(id, RuleTypeCEL).
+			WithKubernetesInput("pods", "", "v1", "pods", "", "").
+			SetCelExpression("pods.items.size() > 0").
+			BuildCelRule()
+		if err != nil {
+			t.Fatalf("Failed to build rule: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func TestScanner_Environment(t *testing.T) {
+	t.Run("shared by all results", func(t *testing.T) {
+		fetcher := &clusterFetcher{
+			countingFetcher: countingFetcher{data: map[string]interface{}{"pods": podList(1)}},
+			info:            ClusterInfo{KubernetesVersion: "v1.31.6", Platform: "OpenShift"},
+		}
+		s := NewScanner(fetcher, &TestLogger{t: t})
+		results, err := s.Scan(context.Background(), ScanConfig{Rules: environmentRules(t)})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		environment := results[0].Metadata.Environment
+		if environment != results[1].Metadata.Environment {
+			t.Errorf("Expected identical environments, got %+v and %+v", environment, results[1].Metadata.Environment)
+		}
+		if environment.ScanID == "" || environment.SDKVersion == "" || environment.StartTime.IsZero() {
+			t.Errorf("Expected scan ID, SDK version and start time, got %+v", environment)
+		}
+		if environment.EndTime.Before(environment.StartTime) {
+			t.Errorf("Expected the scan to end after it started, got %+v", environment)
+		}
+		if environment.KubernetesVersion != "v1.31.6" || environment.Platform != "OpenShift" {
+			t.Errorf("Expected the cluster to be described, got %+v", environment)
+		}
+		if s.LastScanEnvironment() != environment {
+			t.Errorf("Expected LastScanEnvironment to match the results, got %+v", s.LastScanEnvironment())
+		}
+
+		// Every scan gets its own ID
+		results, _ = s.Scan(context.Background(), ScanConfig{Rules: environmentRules(t)})
+		if results[0].Metadata.Environment.ScanID == environment.ScanID {
+			t.Error("Expected a new scan ID for the second scan")
+		}
+	})
+
+	t.Run("configured scan ID", func(t *testing.T) {
+		fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(1)}}
+		results, _ := NewScanner(fetcher, &TestLogger{t: t}).Scan(context.Background(), ScanConfig{Rules: environmentRules(t), ScanID: "nightly-42"})
+		if got := results[0].Metadata.Environment; got.ScanID != "nightly-42" || got.KubernetesVersion != "" {
+			t.Errorf("Expected the configured scan ID without cluster information, got %+v", got)
+		}
+	})
+
+	t.Run("cluster detection failure", func(t *testing.T) {
+		fetcher := &clusterFetcher{
+			countingFetcher: countingFetcher{data: map[string]interface{}{"pods": podList(1)}},
+			err:             errors.New("forbidden"),
+		}
+		results, _ := NewScanner(fetcher, &TestLogger{t: t}).Scan(context.Background(), ScanConfig{Rules: environmentRules(t)})
+		if results[0].Status != CheckResultPass || results[0].Metadata.Environment.ScanID == "" {
+			t.Errorf("Expected the scan to run without cluster information, got %+v", results[0])
+		}
+	})
+
+	t.Run("streamed results", func(t *testing.T) {
+		fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(1)}}
+		s := NewScanner(fetcher, &TestLogger{t: t})
+		var streamed []CheckResult
+		err := s.ScanWithSink(context.Background(), ScanConfig{Rules: environmentRules(t)}, ResultSinkFunc(func(index int, result CheckResult) {
+			streamed = append(streamed, result)
+		}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		environment := streamed[0].Metadata.Environment
+		if environment.ScanID == "" || !environment.EndTime.IsZero() {
+			t.Errorf("Expected a scan ID without end time, got %+v", environment)
+		}
+		if last := s.LastScanEnvironment(); last.ScanID != environment.ScanID || last.EndTime.IsZero() {
+			t.Errorf("Expected the complete environment from LastScanEnvironment, got %+v", last)
+		}
+	})
+}
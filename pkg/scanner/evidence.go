@@ -0,0 +1,346 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultEvidenceMaxBytes is the largest input, once encoded as JSON, whose
+// data is captured as evidence by default
+const DefaultEvidenceMaxBytes = 1 << 20
+
+// RedactedValue replaces redacted fields in captured input data
+const RedactedValue = "REDACTED"
+
+// EvidenceOptions enables evidence capture for a scan
+type EvidenceOptions struct {
+	// IncludeData captures the inputs themselves, not only their digests
+	IncludeData bool `json:"includeData"`
+
+	// MaxDataBytes is the largest encoded input whose data is captured
+	// (0 means DefaultEvidenceMaxBytes); larger inputs are marked truncated
+	MaxDataBytes int `json:"maxDataBytes,omitempty"`
+
+	// RedactPaths lists dot-separated field paths replaced by RedactedValue in
+	// captured data, such as "data" or "metadata.annotations". Paths apply to
+	// an object and to each item of a list, and descend into lists they cross.
+	RedactPaths []string `json:"redactPaths,omitempty"`
+}
+
+// InputEvidence describes the data an input provided to a rule
+type InputEvidence struct {
+	Input       string      `json:"input"`
+	Source      string      `json:"source,omitempty"`     // What the input requested, e.g. "v1/pods in namespace default"
+	Digest      string      `json:"digest"`               // Digest of the data as evaluated
+	ObjectCount int         `json:"objectCount"`          // Items of a list, 1 for a single object, 0 for absent data
+	Data        interface{} `json:"data,omitempty"`       // Captured data, after redaction
+	DataDigest  string      `json:"dataDigest,omitempty"` // Digest of the captured data; differs from Digest when redacted, empty for absent data
+	Redacted    bool        `json:"redacted,omitempty"`   // Fields were redacted from Data
+	Truncated   bool        `json:"truncated,omitempty"`  // Data exceeded MaxDataBytes and was not captured
+}
+
+// collectEvidence describes the inputs a rule was evaluated with
+func (s *Scanner) collectEvidence(run *scanRun, rule Rule, resourceMap map[string]interface{}) []InputEvidence {
+	options := run.config.Evidence
+	var evidence []InputEvidence
+
+	for _, input := range rule.Inputs() {
+		data, ok := resourceMap[input.Name()]
+		if !ok {
+			continue
+		}
+
+		item := InputEvidence{
+			Input:       input.Name(),
+			Source:      describeInputSource(input),
+			ObjectCount: objectCount(data),
+		}
+		digest, err := run.snapshot.Digest(input, data)
+		if err != nil {
+			s.logger.Warn("Rule %s: cannot capture evidence for input %s: %v", rule.Identifier(), input.Name(), err)
+			continue
+		}
+		item.Digest = "sha256:" + digest
+
+		if options.IncludeData {
+			captureData(&item, data, options)
+		}
+		evidence = append(evidence, item)
+	}
+
+	return evidence
+}
+
+// captureData copies data into the evidence, redacted and size-capped.
+// Absent data, such as an optional object that does not exist, is described
+// by Digest alone.
+func captureData(item *InputEvidence, data interface{}, options *EvidenceOptions) {
+	if data == nil {
+		return
+	}
+	maxBytes := options.MaxDataBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultEvidenceMaxBytes
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil || len(encoded) > maxBytes {
+		item.Truncated = true
+		return
+	}
+
+	// Work on a copy: fetched data is shared between rules
+	captured, err := decodeJSON(encoded)
+	if err != nil {
+		item.Truncated = true
+		return
+	}
+	for _, path := range options.RedactPaths {
+		if redactPath(captured, strings.Split(path, ".")) {
+			item.Redacted = true
+		}
+	}
+
+	item.Data = captured
+	item.DataDigest = item.Digest
+	if item.Redacted {
+		digest, _ := digestData(captured)
+		item.DataDigest = "sha256:" + digest
+	}
+}
+
+// redactPath replaces the field at path in value, in each item of a list and
+// in each element of the lists the path crosses. It reports whether a field was replaced.
+func redactPath(value interface{}, path []string) bool {
+	switch typed := value.(type) {
+	case []interface{}:
+		redacted := false
+		for _, element := range typed {
+			if redactPath(element, path) {
+				redacted = true
+			}
+		}
+		return redacted
+	case map[string]interface{}:
+		// A list object may hold the field itself as well as in its items
+		redacted := false
+		if items, ok := typed["items"].([]interface{}); ok && path[0] != "items" {
+			redacted = redactPath(items, path)
+		}
+		child, ok := typed[path[0]]
+		if !ok {
+			return redacted
+		}
+		if len(path) == 1 {
+			typed[path[0]] = RedactedValue
+			return true
+		}
+		return redactPath(child, path[1:]) || redacted
+	default:
+		return false
+	}
+}
+
+// objectCount returns the number of objects in fetched input data
+func objectCount(data interface{}) int {
+	if data == nil {
+		return 0
+	}
+	if list, ok := data.(map[string]interface{}); ok {
+		if items, ok := list["items"].([]interface{}); ok {
+			return len(items)
+		}
+	}
+	return 1
+}
+
+// EvidenceBundleManifest lists the files of an evidence bundle with their digests
+type EvidenceBundleManifest struct {
+	Version   string            `json:"version"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Files     map[string]string `json:"files"` // Digests keyed by path relative to the bundle
+}
+
+const (
+	evidenceBundleVersion = "1"
+	evidenceManifestFile  = "manifest.json"
+	evidenceResultsFile   = "results.json"
+	evidenceDataDir       = "evidence"
+)
+
+// WriteEvidenceBundle writes results and their evidence to dir. Captured
+// input data is stored once per digest under evidence/ and referenced from
+// results.json; manifest.json records the digest of every file so that the
+// bundle can be checked with VerifyEvidenceBundle.
+func WriteEvidenceBundle(dir string, results []CheckResult) error {
+	if err := os.MkdirAll(filepath.Join(dir, evidenceDataDir), 0755); err != nil {
+		return fmt.Errorf("failed to create evidence bundle %s: %w", dir, err)
+	}
+
+	manifest := EvidenceBundleManifest{
+		Version:   evidenceBundleVersion,
+		CreatedAt: time.Now().UTC(),
+		Files:     make(map[string]string),
+	}
+	writeFile := func(name string, value interface{}) error {
+		encoded, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), encoded, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		manifest.Files[filepath.ToSlash(name)] = fileDigest(encoded)
+		return nil
+	}
+
+	// Data is moved out of the results into content-addressed files
+	stripped := make([]CheckResult, len(results))
+	for i, result := range results {
+		stripped[i] = result
+		stripped[i].Evidence = make([]InputEvidence, len(result.Evidence))
+		for j, item := range result.Evidence {
+			if item.Data != nil {
+				name := filepath.Join(evidenceDataDir, evidenceFileName(item.DataDigest))
+				if _, ok := manifest.Files[filepath.ToSlash(name)]; !ok {
+					if err := writeFile(name, item.Data); err != nil {
+						return err
+					}
+				}
+				item.Data = nil
+			}
+			stripped[i].Evidence[j] = item
+		}
+	}
+
+	if err := writeFile(evidenceResultsFile, stripped); err != nil {
+		return err
+	}
+
+	// The manifest is written last so that a bundle is only complete with it
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", evidenceManifestFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, evidenceManifestFile), encoded, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", evidenceManifestFile, err)
+	}
+	return nil
+}
+
+// VerifyEvidenceBundle checks every file of a bundle written by
+// WriteEvidenceBundle against the manifest, and every captured input against
+// the digest recorded in the results. It returns the results of the bundle.
+func VerifyEvidenceBundle(dir string) ([]CheckResult, error) {
+	var manifest EvidenceBundleManifest
+	if err := readJSONFile(filepath.Join(dir, evidenceManifestFile), &manifest); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(manifest.Files))
+	for name := range manifest.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, fmt.Errorf("evidence bundle file %s: %w", name, err)
+		}
+		if digest := fileDigest(content); digest != manifest.Files[name] {
+			return nil, fmt.Errorf("evidence bundle file %s has digest %s, manifest records %s", name, digest, manifest.Files[name])
+		}
+	}
+
+	var results []CheckResult
+	if err := readJSONFile(filepath.Join(dir, evidenceResultsFile), &results); err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		for _, item := range result.Evidence {
+			if item.DataDigest == "" {
+				continue
+			}
+			name := filepath.ToSlash(filepath.Join(evidenceDataDir, evidenceFileName(item.DataDigest)))
+			if _, ok := manifest.Files[name]; !ok {
+				return nil, fmt.Errorf("rule %s: evidence for input %s is missing from the bundle", result.ID, item.Input)
+			}
+
+			content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			data, err := decodeJSON(content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+			}
+			digest, err := digestData(data)
+			if err != nil || "sha256:"+digest != item.DataDigest {
+				return nil, fmt.Errorf("rule %s: evidence for input %s does not match digest %s", result.ID, item.Input, item.DataDigest)
+			}
+			if !item.Redacted && item.DataDigest != item.Digest {
+				return nil, fmt.Errorf("rule %s: evidence for input %s does not match the evaluated data", result.ID, item.Input)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// decodeJSON decodes captured data, keeping numbers as written so that the
+// data encodes to the same digest again
+func decodeJSON(content []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// evidenceFileName returns the file name of captured data with the given digest
+func evidenceFileName(digest string) string {
+	return strings.TrimPrefix(digest, "sha256:") + ".json"
+}
+
+// fileDigest returns the digest of a bundle file
+func fileDigest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// readJSONFile decodes a JSON file of an evidence bundle
+func readJSONFile(path string, value interface{}) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(content, value); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func secretList() map[string]interface{} {
+	return map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "tls", "annotations": map[string]interface{}{"owner": "ops"}},
+				"data":     map[string]interface{}{"tls.key": "c2VjcmV0"},
+				"type":     "kubernetes.io/tls",
+			},
+		},
+	}
+}
+
+func evidenceScan(t *testing.T, options *EvidenceOptions) []CheckResult {
+	t.Helper()
+	var rules []Rule
+	for _, id := range []string{"tls-secrets", "typed-secrets"} {
+		rule, err := NewRuleBuilder(id, RuleTypeCEL).
+			WithKubernetesInput("secrets", "", "v1", "secrets", "default", "").
+			WithKubernetesInput("pods", "", "v1", "pods", "default", "").
+			SetCelExpression("secrets.items.all(s, s.type != '') && pods.items.size() >= 0").
+			BuildCelRule()
+		if err != nil {
+			t.Fatalf("Failed to build rule: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	fetcher := &countingFetcher{data: map[string]interface{}{"secrets": secretList(), "pods": podList(2)}}
+	results, err := NewScanner(fetcher, &TestLogger{t: t}).Scan(context.Background(), ScanConfig{Rules: rules, Evidence: options})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return results
+}
+
+func TestScanner_Evidence(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		results := evidenceScan(t, nil)
+		if results[0].Evidence != nil {
+			t.Errorf("Expected no evidence, got %+v", results[0].Evidence)
+		}
+	})
+
+	t.Run("digests and counts", func(t *testing.T) {
+		results := evidenceScan(t, &EvidenceOptions{})
+		evidence := results[0].Evidence
+		if len(evidence) != 2 || evidence[0].Input != "secrets" || evidence[1].ObjectCount != 2 {
+			t.Fatalf("Unexpected evidence: %+v", evidence)
+		}
+		if !strings.HasPrefix(evidence[0].Digest, "sha256:") || evidence[0].Data != nil {
+			t.Errorf("Expected a digest without data, got %+v", evidence[0])
+		}
+		if evidence[0].Source != "v1/secrets in namespace default" {
+			t.Errorf("Unexpected source %q", evidence[0].Source)
+		}
+	})
+
+	t.Run("redacted data", func(t *testing.T) {
+		results := evidenceScan(t, &EvidenceOptions{IncludeData: true, RedactPaths: []string{"data", "metadata.annotations"}})
+		secrets := results[0].Evidence[0]
+		if !secrets.Redacted || secrets.DataDigest == secrets.Digest {
+			t.Fatalf("Expected redacted evidence with its own digest, got %+v", secrets)
+		}
+		item := secrets.Data.(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})
+		if item["data"] != RedactedValue || item["metadata"].(map[string]interface{})["annotations"] != RedactedValue {
+			t.Errorf("Expected data and annotations to be redacted, got %v", item)
+		}
+		if item["type"] != "kubernetes.io/tls" {
+			t.Errorf("Expected other fields to be kept, got %v", item)
+		}
+
+		// The fetched data shared with the rules is left untouched
+		if results[0].Status != CheckResultPass {
+			t.Errorf("Expected PASS, got %s", results[0].Status)
+		}
+		pods := results[0].Evidence[1]
+		if pods.Redacted || pods.DataDigest != pods.Digest {
+			t.Errorf("Expected unredacted pods evidence, got %+v", pods)
+		}
+	})
+
+	t.Run("size cap", func(t *testing.T) {
+		results := evidenceScan(t, &EvidenceOptions{IncludeData: true, MaxDataBytes: 16})
+		if secrets := results[0].Evidence[0]; !secrets.Truncated || secrets.Data != nil || secrets.Digest == "" {
+			t.Errorf("Expected truncated evidence with a digest, got %+v", secrets)
+		}
+	})
+}
+
+func TestRedactPath(t *testing.T) {
+	list := secretList()
+	list["metadata"] = map[string]interface{}{"annotations": map[string]interface{}{"token": "c2VjcmV0"}}
+
+	if !redactPath(list, []string{"metadata", "annotations"}) {
+		t.Fatal("Expected the path to be redacted")
+	}
+	if annotations := list["metadata"].(map[string]interface{})["annotations"]; annotations != RedactedValue {
+		t.Errorf("Expected the annotations of the list to be redacted, got %v", annotations)
+	}
+	item := list["items"].([]interface{})[0].(map[string]interface{})
+	if annotations := item["metadata"].(map[string]interface{})["annotations"]; annotations != RedactedValue {
+		t.Errorf("Expected the annotations of the item to be redacted, got %v", annotations)
+	}
+
+	if redactPath(secretList(), []string{"metadata", "labels"}) {
+		t.Error("Expected nothing to be redacted for an absent path")
+	}
+}
+
+func TestEvidenceBundle(t *testing.T) {
+	results := evidenceScan(t, &EvidenceOptions{IncludeData: true, RedactPaths: []string{"data"}})
+	dir := t.TempDir()
+
+	if err := WriteEvidenceBundle(dir, results); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+
+	// Inputs shared by both rules are stored once
+	files, err := os.ReadDir(filepath.Join(dir, "evidence"))
+	if err != nil || len(files) != 2 {
+		t.Fatalf("Expected 2 evidence files, got %d (%v)", len(files), err)
+	}
+
+	verified, err := VerifyEvidenceBundle(dir)
+	if err != nil {
+		t.Fatalf("Expected the bundle to verify: %v", err)
+	}
+	if len(verified) != 2 || verified[0].Evidence[0].DataDigest != results[0].Evidence[0].DataDigest {
+		t.Errorf("Unexpected verified results: %+v", verified)
+	}
+
+	t.Run("absent optional input", func(t *testing.T) {
+		rule, err := NewRuleBuilder("optional-settings", RuleTypeCEL).
+			WithKubernetesInput("settings", "", "v1", "configmaps", "default", "settings").
+			MarkInputOptional("settings").
+			SetCelExpression("settings == null").
+			BuildCelRule()
+		if err != nil {
+			t.Fatalf("Failed to build rule: %v", err)
+		}
+		// The fetcher binds the absent object as null
+		fetcher := &countingFetcher{data: map[string]interface{}{"settings": nil}}
+		results, err := NewScanner(fetcher, &TestLogger{t: t}).Scan(context.Background(),
+			ScanConfig{Rules: []Rule{rule}, Evidence: &EvidenceOptions{IncludeData: true}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(results[0].Evidence) != 1 || results[0].Evidence[0].Digest == "" || results[0].Evidence[0].DataDigest != "" {
+			t.Fatalf("Expected absent data to be described by its digest only, got %+v", results[0].Evidence)
+		}
+
+		dir := t.TempDir()
+		if err := WriteEvidenceBundle(dir, results); err != nil {
+			t.Fatalf("Failed to write bundle: %v", err)
+		}
+		if _, err := VerifyEvidenceBundle(dir); err != nil {
+			t.Errorf("Expected a bundle with an absent input to verify: %v", err)
+		}
+	})
+
+	// Tampering with captured data is detected
+	tampered := filepath.Join(dir, "evidence", files[0].Name())
+	if err := os.WriteFile(tampered, []byte(`{"items": []}`), 0644); err != nil {
+		t.Fatalf("Failed to tamper with bundle: %v", err)
+	}
+	if _, err := VerifyEvidenceBundle(dir); err == nil {
+		t.Error("Expected a tampered bundle to fail verification")
+	}
+}
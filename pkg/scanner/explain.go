@@ -0,0 +1,421 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/parser"
+)
+
+// DefaultExplainMaxOffenders is the number of offending elements listed for
+// each comprehension by default
+const DefaultExplainMaxOffenders = 5
+
+// ExplainOptions enables explanations of failing rules
+type ExplainOptions struct {
+	// MaxOffenders is the number of offending elements listed for each
+	// comprehension (0 means DefaultExplainMaxOffenders)
+	MaxOffenders int `json:"maxOffenders,omitempty"`
+}
+
+// Explanation tells which parts of a failing expression were false
+type Explanation struct {
+	Clauses []ClauseExplanation `json:"clauses"` // Top-level conjuncts of the expression, in order
+}
+
+// ClauseExplanation is the outcome of one conjunct of an expression
+type ClauseExplanation struct {
+	Expression    string     `json:"expression"`
+	Value         string     `json:"value"`                   // "true", "false" or the evaluation error
+	Offenders     []Offender `json:"offenders,omitempty"`     // Elements for which the predicate of an all() was false
+	OffenderCount int        `json:"offenderCount,omitempty"` // Number of offending elements, including those not listed
+}
+
+// Offender is an element that made an all() comprehension false
+type Offender struct {
+	Path    string              `json:"path"`              // Where the element was read from, e.g. pods.items[1].spec.containers[0]
+	Name    string              `json:"name,omitempty"`    // metadata.name or name of the element
+	Value   interface{}         `json:"value,omitempty"`   // The element, when its predicate has no nested all() to explain
+	Clauses []ClauseExplanation `json:"clauses,omitempty"` // Why the predicate was false for the element
+}
+
+// maxOffenders returns the number of offenders listed per comprehension
+func (o *ExplainOptions) maxOffenders() int {
+	if o.MaxOffenders > 0 {
+		return o.MaxOffenders
+	}
+	return DefaultExplainMaxOffenders
+}
+
+// tracedKeySuffix tells the traced program of an expression apart from its
+// program in the program cache
+const tracedKeySuffix = "|traced"
+
+// explainer evaluates the parts of a failing expression. The expression is
+// compiled again with macro tracking so that its parts can be printed, and
+// evaluated exhaustively with state tracking so that every top-level conjunct
+// has a value. Predicates of comprehensions are evaluated for each element.
+type explainer struct {
+	ctx        context.Context
+	traced     *compiledProgram
+	info       *celast.SourceInfo
+	activation map[string]interface{}
+	options    *ExplainOptions
+}
+
+// tracedProgram returns the expression of compiled compiled again for
+// explanations. It is kept in the program cache next to compiled, so the
+// programs of its parts are shared by every explanation of the expression.
+func (s *Scanner) tracedProgram(compiled *compiledProgram, expression string) (*compiledProgram, error) {
+	key := compiled.key + tracedKeySuffix
+	if traced, cached := s.lookupProgram(key); cached {
+		return traced, nil
+	}
+
+	env, err := compiled.env.Extend(cel.EnableMacroCallTracking())
+	if err != nil {
+		return nil, err
+	}
+	checked, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	programOpts := append(append([]cel.ProgramOption(nil), compiled.options...), cel.EvalOptions(cel.OptExhaustiveEval))
+	program, err := env.Program(checked, programOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	traced := &compiledProgram{
+		key:     key,
+		env:     env,
+		ast:     checked,
+		program: program,
+		options: compiled.options,
+		parts:   make(map[string]cel.Program),
+	}
+	s.storeProgram(traced)
+	return traced, nil
+}
+
+// explain returns why expression evaluated to false against activation
+func (s *Scanner) explain(ctx context.Context, compiled *compiledProgram, expression string, activation map[string]interface{}, config ScanConfig) (*Explanation, error) {
+	traced, err := s.tracedProgram(compiled, expression)
+	if err != nil {
+		return nil, err
+	}
+	_, details, err := traced.program.ContextEval(ctx, activation)
+	if details == nil || details.State() == nil {
+		return nil, fmt.Errorf("failed to trace expression: %v", err)
+	}
+
+	e := &explainer{
+		ctx:        ctx,
+		traced:     traced,
+		info:       traced.ast.NativeRep().SourceInfo(),
+		activation: activation,
+		options:    config.Explain,
+	}
+	state := details.State()
+	clauses := e.explainClauses(traced.ast.NativeRep().Expr(), nil, func(part celast.Expr) ref.Val {
+		value, ok := state.Value(part.ID())
+		if !ok {
+			return types.NewErr("not evaluated")
+		}
+		return value
+	})
+	return &Explanation{Clauses: clauses}, nil
+}
+
+// scopeVar is a comprehension variable bound while explaining a predicate
+type scopeVar struct {
+	value ref.Val
+	path  string
+}
+
+// explainClauses splits e into its conjuncts and explains each of them, using
+// eval to obtain the value of a part of e
+func (e *explainer) explainClauses(expr celast.Expr, scope map[string]scopeVar, eval func(celast.Expr) ref.Val) []ClauseExplanation {
+	var clauses []ClauseExplanation
+	for _, conjunct := range conjuncts(expr) {
+		clause := ClauseExplanation{Expression: e.unparse(conjunct)}
+		value := eval(conjunct)
+		clause.Value = describeValue(value)
+		if value == types.False && conjunct.Kind() == celast.ComprehensionKind {
+			e.explainComprehension(&clause, conjunct.AsComprehension(), scope, eval)
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses
+}
+
+// explainComprehension lists the elements for which the predicate of a false
+// all() comprehension was false
+func (e *explainer) explainComprehension(clause *ClauseExplanation, comprehension celast.ComprehensionExpr, scope map[string]scopeVar, eval func(celast.Expr) ref.Val) {
+	predicate, ok := allPredicate(comprehension)
+	if !ok || comprehension.HasIterVar2() {
+		return
+	}
+	names := []string{comprehension.IterVar()}
+	for name := range scope {
+		if name != comprehension.IterVar() {
+			names = append(names, name)
+		}
+	}
+	program, err := e.compilePart(predicate, names)
+	if err != nil {
+		return
+	}
+
+	rangePath := e.renderPath(comprehension.IterRange(), scope)
+	for _, element := range rangeValues(eval(comprehension.IterRange()), rangePath) {
+		inner := make(map[string]scopeVar, len(scope)+1)
+		for name, bound := range scope {
+			inner[name] = bound
+		}
+		inner[comprehension.IterVar()] = element
+
+		out, _, err := program.ContextEval(e.ctx, e.bind(inner))
+		if err != nil || out != types.False {
+			continue
+		}
+		clause.OffenderCount++
+		if len(clause.Offenders) >= e.options.maxOffenders() {
+			continue
+		}
+
+		offender := Offender{Path: element.path, Name: elementName(element.value)}
+		if hasAllComprehension(predicate) {
+			offender.Clauses = e.explainClauses(predicate, inner, func(part celast.Expr) ref.Val {
+				return e.evalPart(part, inner)
+			})
+		} else {
+			offender.Value = element.value.Value()
+		}
+		clause.Offenders = append(clause.Offenders, offender)
+	}
+}
+
+// evalPart evaluates a part of a predicate with the variables in scope
+func (e *explainer) evalPart(part celast.Expr, scope map[string]scopeVar) ref.Val {
+	names := make([]string, 0, len(scope))
+	for name := range scope {
+		names = append(names, name)
+	}
+	program, err := e.compilePart(part, names)
+	if err != nil {
+		return types.NewErr("%v", err)
+	}
+	out, _, err := program.ContextEval(e.ctx, e.bind(scope))
+	if err != nil {
+		return types.NewErr("%v", err)
+	}
+	return out
+}
+
+// compilePart returns the program of a part of the expression, declaring the
+// comprehension variables it may refer to. Programs are compiled once and
+// kept with the traced program.
+func (e *explainer) compilePart(part celast.Expr, names []string) (cel.Program, error) {
+	sort.Strings(names)
+	key := fmt.Sprintf("%d|%s", part.ID(), strings.Join(names, ","))
+
+	e.traced.partsMu.Lock()
+	defer e.traced.partsMu.Unlock()
+	if program, ok := e.traced.parts[key]; ok {
+		return program, nil
+	}
+
+	opts := make([]cel.EnvOption, 0, len(names))
+	for _, name := range names {
+		opts = append(opts, cel.Variable(name, cel.DynType))
+	}
+	env, err := e.traced.env.Extend(opts...)
+	if err != nil {
+		return nil, err
+	}
+	checked, issues := env.Compile(e.unparse(part))
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	program, err := env.Program(checked, e.traced.options...)
+	if err != nil {
+		return nil, err
+	}
+	e.traced.parts[key] = program
+	return program, nil
+}
+
+// bind returns the activation with the comprehension variables in scope
+func (e *explainer) bind(scope map[string]scopeVar) map[string]interface{} {
+	activation := make(map[string]interface{}, len(e.activation)+len(scope))
+	for name, value := range e.activation {
+		activation[name] = value
+	}
+	for name, bound := range scope {
+		activation[name] = bound.value
+	}
+	return activation
+}
+
+// unparse prints a part of the expression
+func (e *explainer) unparse(part celast.Expr) string {
+	text, err := parser.Unparse(part, e.info)
+	if err != nil {
+		return fmt.Sprintf("<expression %d>", part.ID())
+	}
+	return text
+}
+
+// renderPath prints where a range was read from, replacing comprehension
+// variables by the path of the element they are bound to
+func (e *explainer) renderPath(expr celast.Expr, scope map[string]scopeVar) string {
+	switch expr.Kind() {
+	case celast.IdentKind:
+		if bound, ok := scope[expr.AsIdent()]; ok {
+			return bound.path
+		}
+		return expr.AsIdent()
+	case celast.SelectKind:
+		sel := expr.AsSelect()
+		if !sel.IsTestOnly() {
+			return appendPath(e.renderPath(sel.Operand(), scope), sel.FieldName())
+		}
+	case celast.CallKind:
+		call := expr.AsCall()
+		if call.FunctionName() == operators.Index {
+			if key, ok := literalKey(call.Args()[1]); ok {
+				return appendPath(e.renderPath(call.Args()[0], scope), key)
+			}
+		}
+	}
+	return e.unparse(expr)
+}
+
+// conjuncts flattens the top-level && operators of an expression
+func conjuncts(expr celast.Expr) []celast.Expr {
+	if expr.Kind() == celast.CallKind && expr.AsCall().FunctionName() == operators.LogicalAnd {
+		var parts []celast.Expr
+		for _, arg := range expr.AsCall().Args() {
+			parts = append(parts, conjuncts(arg)...)
+		}
+		return parts
+	}
+	return []celast.Expr{expr}
+}
+
+// allPredicate returns the predicate of a comprehension expanded from the
+// all() macro, whose loop step is accu && predicate
+func allPredicate(comprehension celast.ComprehensionExpr) (celast.Expr, bool) {
+	step := comprehension.LoopStep()
+	if step.Kind() != celast.CallKind || step.AsCall().FunctionName() != operators.LogicalAnd {
+		return nil, false
+	}
+	args := step.AsCall().Args()
+	if len(args) != 2 || args[0].Kind() != celast.IdentKind || args[0].AsIdent() != comprehension.AccuVar() {
+		return nil, false
+	}
+	return args[1], true
+}
+
+// hasAllComprehension reports whether a predicate contains an all()
+// comprehension among its conjuncts that could explain it further
+func hasAllComprehension(predicate celast.Expr) bool {
+	for _, conjunct := range conjuncts(predicate) {
+		if conjunct.Kind() == celast.ComprehensionKind {
+			if _, ok := allPredicate(conjunct.AsComprehension()); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rangeValues returns the elements of a list, or the keys of a map in order,
+// with the path each was read from
+func rangeValues(value ref.Val, path string) []scopeVar {
+	switch typed := value.(type) {
+	case traits.Lister:
+		size, _ := typed.Size().(types.Int)
+		elements := make([]scopeVar, 0, int(size))
+		for i := types.Int(0); i < size; i++ {
+			elements = append(elements, scopeVar{value: typed.Get(i), path: appendPath(path, int64(i))})
+		}
+		return elements
+	case traits.Mapper:
+		var elements []scopeVar
+		for it := typed.Iterator(); it.HasNext() == types.True; {
+			key := it.Next()
+			elements = append(elements, scopeVar{value: key, path: appendPath(path, key.Value())})
+		}
+		sort.Slice(elements, func(i, j int) bool { return elements[i].path < elements[j].path })
+		return elements
+	}
+	return nil
+}
+
+// elementName returns the metadata.name or name field of an element
+func elementName(value ref.Val) string {
+	object, ok := value.Value().(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		if name, ok := metadata["name"].(string); ok {
+			return name
+		}
+	}
+	name, _ := object["name"].(string)
+	return name
+}
+
+// describeValue prints the value of a conjunct
+func describeValue(value ref.Val) string {
+	switch typed := value.(type) {
+	case *types.Err:
+		return "error: " + typed.String()
+	case *types.Unknown:
+		return "unknown"
+	case nil:
+		return "unknown"
+	}
+	return fmt.Sprintf("%v", value.Value())
+}
+
+// explanationFor explains a false evaluation when the scan asks for it,
+// logging rather than failing the rule when the expression cannot be traced
+func (s *Scanner) explanationFor(ctx context.Context, compiled *compiledProgram, rule Rule, activation map[string]interface{}, config ScanConfig) *Explanation {
+	celRule, ok := rule.(CelRule)
+	if config.Explain == nil || !ok {
+		return nil
+	}
+	explanation, err := s.explain(ctx, compiled, celRule.Expression(), activation, config)
+	if err != nil {
+		s.logger.Warn("Rule %s: cannot explain failure: %v", rule.Identifier(), err)
+		return nil
+	}
+	return explanation
+}
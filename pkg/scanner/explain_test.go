@@ -0,0 +1,177 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func explainPods() map[string]interface{} {
+	container := func(name string, escalation bool) interface{} {
+		return map[string]interface{}{
+			"name":            name,
+			"securityContext": map[string]interface{}{"allowPrivilegeEscalation": escalation},
+		}
+	}
+	pod := func(name string, containers ...interface{}) interface{} {
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name},
+			"spec":     map[string]interface{}{"hostNetwork": false, "containers": containers},
+		}
+	}
+	return map[string]interface{}{"items": []interface{}{
+		pod("web", container("nginx", false)),
+		pod("debug", container("app", false), container("shell", true)),
+	}}
+}
+
+func explainScan(t *testing.T, expression string, options *ExplainOptions) CheckResult {
+	t.Helper()
+	rule, err := NewRuleBuilder("explained", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		SetCelExpression(expression).
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+
+	fetcher := &countingFetcher{data: map[string]interface{}{"pods": explainPods()}}
+	results, err := NewScanner(fetcher, &TestLogger{t: t}).Scan(context.Background(), ScanConfig{Rules: []Rule{rule}, Explain: options})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return results[0]
+}
+
+func TestScanner_Explain(t *testing.T) {
+	noEscalation := "pods.items.all(p, p.spec.containers.all(c, c.securityContext.allowPrivilegeEscalation == false))"
+
+	t.Run("disabled by default", func(t *testing.T) {
+		result := explainScan(t, noEscalation, nil)
+		if result.Status != CheckResultFail || result.Explanation != nil {
+			t.Errorf("Expected FAIL without explanation, got %s %+v", result.Status, result.Explanation)
+		}
+	})
+
+	t.Run("passing rules are not explained", func(t *testing.T) {
+		result := explainScan(t, "pods.items.size() == 2", &ExplainOptions{})
+		if result.Status != CheckResultPass || result.Explanation != nil {
+			t.Errorf("Expected PASS without explanation, got %s %+v", result.Status, result.Explanation)
+		}
+	})
+
+	t.Run("false conjuncts", func(t *testing.T) {
+		result := explainScan(t, "pods.items.size() == 2 && pods.items.size() > 5 && pods.items.all(p, p.spec.hostNetwork == false)", &ExplainOptions{})
+		if result.Explanation == nil || len(result.Explanation.Clauses) != 3 {
+			t.Fatalf("Expected 3 clauses, got %+v", result.Explanation)
+		}
+		clauses := result.Explanation.Clauses
+		if clauses[0].Value != "true" || clauses[1].Value != "false" || clauses[2].Value != "true" {
+			t.Errorf("Unexpected clause values: %+v", clauses)
+		}
+		if clauses[1].Expression != "pods.items.size() > 5" {
+			t.Errorf("Unexpected clause expression %q", clauses[1].Expression)
+		}
+	})
+
+	t.Run("nested offenders", func(t *testing.T) {
+		result := explainScan(t, noEscalation, &ExplainOptions{})
+		clause := result.Explanation.Clauses[0]
+		if clause.Value != "false" || clause.OffenderCount != 1 {
+			t.Fatalf("Expected one offending pod, got %+v", clause)
+		}
+		pod := clause.Offenders[0]
+		if pod.Path != "pods.items[1]" || pod.Name != "debug" || len(pod.Clauses) != 1 {
+			t.Fatalf("Unexpected offending pod: %+v", pod)
+		}
+		containers := pod.Clauses[0]
+		if containers.OffenderCount != 1 || containers.Offenders[0].Path != "pods.items[1].spec.containers[1]" || containers.Offenders[0].Name != "shell" {
+			t.Errorf("Expected the shell container to be reported, got %+v", containers)
+		}
+		if containers.Offenders[0].Value == nil {
+			t.Error("Expected the offending container to be included")
+		}
+	})
+
+	t.Run("offender limit", func(t *testing.T) {
+		result := explainScan(t, "pods.items.all(p, p.metadata.name == 'none')", &ExplainOptions{MaxOffenders: 1})
+		clause := result.Explanation.Clauses[0]
+		if clause.OffenderCount != 2 || len(clause.Offenders) != 1 {
+			t.Errorf("Expected 1 of 2 offenders listed, got %+v", clause)
+		}
+	})
+
+	t.Run("per object", func(t *testing.T) {
+		rule, err := NewRuleBuilder("per-pod", RuleTypeCEL).
+			WithKubernetesInput("pods", "", "v1", "pods", "", "").
+			ForEachObject("pods").
+			SetCelExpression("object.spec.containers.all(c, c.securityContext.allowPrivilegeEscalation == false)").
+			BuildCelRule()
+		if err != nil {
+			t.Fatalf("Failed to build rule: %v", err)
+		}
+		fetcher := &countingFetcher{data: map[string]interface{}{"pods": explainPods()}}
+		results, err := NewScanner(fetcher, &TestLogger{t: t}).Scan(context.Background(), ScanConfig{Rules: []Rule{rule}, Explain: &ExplainOptions{}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		findings := results[0].Findings
+		if findings[0].Explanation != nil || findings[1].Explanation == nil {
+			t.Fatalf("Expected only the failing object to be explained: %+v", findings)
+		}
+		offenders := findings[1].Explanation.Clauses[0].Offenders
+		if len(offenders) != 1 || offenders[0].Path != "object.spec.containers[1]" {
+			t.Errorf("Unexpected offenders: %+v", offenders)
+		}
+	})
+
+	t.Run("traced programs are cached", func(t *testing.T) {
+		rule, err := NewRuleBuilder("per-pod", RuleTypeCEL).
+			WithKubernetesInput("pods", "", "v1", "pods", "", "").
+			ForEachObject("pods").
+			SetCelExpression("object.spec.containers.all(c, c.securityContext.allowPrivilegeEscalation == false)").
+			BuildCelRule()
+		if err != nil {
+			t.Fatalf("Failed to build rule: %v", err)
+		}
+		pods := explainPods()
+		debug := pods["items"].([]interface{})[1]
+		pods["items"] = append(pods["items"].([]interface{}), debug, debug, debug)
+
+		scanner := NewScanner(&countingFetcher{data: map[string]interface{}{"pods": pods}}, &TestLogger{t: t})
+		config := ScanConfig{Rules: []Rule{rule}, Explain: &ExplainOptions{}}
+		if _, err := scanner.Scan(context.Background(), config); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		misses := scanner.ProgramCache().Stats().Misses
+		if _, err := scanner.Scan(context.Background(), config); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		stats := scanner.ProgramCache().Stats()
+		if stats.Size != 2 || stats.Misses != misses {
+			t.Errorf("Expected the rule and its traced program to be compiled once, got %+v (misses after first scan: %d)", stats, misses)
+		}
+		for _, element := range scanner.ProgramCache().entries {
+			compiled := element.Value.(*compiledProgram)
+			if strings.HasSuffix(compiled.key, tracedKeySuffix) && len(compiled.parts) != 1 {
+				t.Errorf("Expected the predicate to be compiled once for every failing pod, got %d part programs", len(compiled.parts))
+			}
+		}
+	})
+}
@@ -0,0 +1,184 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FetchFailurePolicy decides the result of a rule when some of its inputs
+// could not be fetched
+type FetchFailurePolicy string
+
+const (
+	// FetchFailurePolicyError reports the rule as ERROR (the default)
+	FetchFailurePolicyError FetchFailurePolicy = "error"
+
+	// FetchFailurePolicyFail reports the rule as FAIL
+	FetchFailurePolicyFail FetchFailurePolicy = "fail"
+
+	// FetchFailurePolicyNotApplicable reports the rule as NOT-APPLICABLE
+	FetchFailurePolicyNotApplicable FetchFailurePolicy = "not-applicable"
+
+	// FetchFailurePolicyEvaluate evaluates the rule with the inputs that were
+	// fetched; expressions referencing a missing input fail to compile
+	FetchFailurePolicyEvaluate FetchFailurePolicy = "evaluate"
+)
+
+// FetchDiagnostic describes an input that could not be fetched
+type FetchDiagnostic struct {
+	Input     string    `json:"input"`
+	InputType InputType `json:"inputType"`
+	Source    string    `json:"source,omitempty"` // The resource, path, service or URL that was requested
+	Reason    string    `json:"reason,omitempty"` // Kubernetes status reason or filesystem error class, such as Forbidden or NotFound
+	Error     string    `json:"error"`
+}
+
+// newFetchDiagnostic describes the failure to fetch input
+func newFetchDiagnostic(input Input, err error) FetchDiagnostic {
+	return FetchDiagnostic{
+		Input:     input.Name(),
+		InputType: input.Type(),
+		Source:    describeInputSource(input),
+		Reason:    fetchErrorReason(err),
+		Error:     err.Error(),
+	}
+}
+
+// describeInputSource names what an input requests
+func describeInputSource(input Input) string {
+	switch spec := input.Spec().(type) {
+	case KubernetesInputSpec:
+		gvr := spec.ResourceType()
+		if spec.ApiGroup() != "" {
+			gvr += "." + spec.ApiGroup()
+		}
+		source := spec.Version() + "/" + gvr
+		if spec.Namespace() != "" {
+			source += " in namespace " + spec.Namespace()
+		}
+		if spec.Name() != "" {
+			source += " named " + spec.Name()
+		}
+		return source
+	case FileInputSpec:
+		return spec.Path()
+	case SystemInputSpec:
+		if spec.ServiceName() != "" {
+			return "service " + spec.ServiceName()
+		}
+		return strings.TrimSpace(spec.Command() + " " + strings.Join(spec.Args(), " "))
+	case HTTPInputSpec:
+		return spec.Method() + " " + spec.URL()
+	}
+	return ""
+}
+
+// fetchErrorReason classifies a fetch error by its Kubernetes status reason
+// or filesystem error
+func fetchErrorReason(err error) string {
+	if reason := apierrors.ReasonForError(err); reason != metav1.StatusReasonUnknown {
+		return string(reason)
+	}
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return string(metav1.StatusReasonNotFound)
+	case errors.Is(err, fs.ErrPermission):
+		return string(metav1.StatusReasonForbidden)
+	}
+	return ""
+}
+
+// IsOptionalInput reports whether an input may be absent
+func IsOptionalInput(input Input) bool {
+	optional, ok := input.(OptionalInput)
+	return ok && optional.Optional()
+}
+
+// IsMissingInputError reports whether a fetch error means the input does not
+// exist: a resource or file that is not found, or a kind that is not served
+func IsMissingInputError(err error) bool {
+	return fetchErrorReason(err) == string(metav1.StatusReasonNotFound) || meta.IsNoMatchError(err)
+}
+
+// AbsentInputValue is the value bound to a missing optional input: an empty
+// list for Kubernetes lists and recursive file inputs, null otherwise
+func AbsentInputValue(input Input) interface{} {
+	switch spec := input.Spec().(type) {
+	case KubernetesInputSpec:
+		if spec.Name() == "" {
+			return map[string]interface{}{"items": []interface{}{}}
+		}
+	case FileInputSpec:
+		if spec.Recursive() {
+			return map[string]interface{}{}
+		}
+	}
+	return nil
+}
+
+// AbsentInputWarning describes a missing optional input bound to its absent value
+func AbsentInputWarning(input Input, err error) string {
+	return fmt.Sprintf("optional input %s not found: %v", input.Name(), err)
+}
+
+// fetchFailureMessage summarizes the inputs that could not be fetched
+func fetchFailureMessage(diagnostics []FetchDiagnostic) string {
+	if len(diagnostics) == 1 {
+		return fmt.Sprintf("Failed to fetch input %s: %s", diagnostics[0].Input, diagnostics[0].Error)
+	}
+	names := make([]string, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		names = append(names, diagnostic.Input)
+	}
+	return fmt.Sprintf("Failed to fetch inputs %s: %s", strings.Join(names, ", "), diagnostics[0].Error)
+}
+
+// applyFetchFailurePolicy returns the result of a rule with inputs that could
+// not be fetched, or false when the rule should be evaluated anyway
+func (s *Scanner) applyFetchFailurePolicy(rule Rule, diagnostics []FetchDiagnostic, warnings []string, policy FetchFailurePolicy) (CheckResult, bool) {
+	message := fetchFailureMessage(diagnostics)
+	s.logger.Error("Rule %s: %s", rule.Identifier(), message)
+
+	result := CheckResult{
+		ID:          rule.Identifier(),
+		Metadata:    CheckResultMetadata{},
+		Warnings:    warnings,
+		FetchErrors: diagnostics,
+	}
+
+	switch policy {
+	case FetchFailurePolicyEvaluate:
+		return CheckResult{}, false
+	case FetchFailurePolicyFail:
+		result.Status = CheckResultFail
+		result.ErrorMessage = message
+	case FetchFailurePolicyNotApplicable:
+		result.Status = CheckResultNotApplicable
+		result.Reason = message
+	default:
+		result.Status = CheckResultError
+		result.ErrorMessage = message
+	}
+	return result, true
+}
@@ -0,0 +1,190 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// failingFetcher returns data, errors and warnings per input name
+type failingFetcher struct {
+	data     map[string]interface{}
+	errs     map[string]error
+	warnings map[string][]string
+}
+
+func (f *failingFetcher) FetchResources(ctx context.Context, rule Rule, variables []CelVariable) (map[string]interface{}, []string, error) {
+	result := make(map[string]interface{})
+	var warnings []string
+	for _, input := range rule.Inputs() {
+		warnings = append(warnings, f.warnings[input.Name()]...)
+		if err, ok := f.errs[input.Name()]; ok {
+			return nil, warnings, fmt.Errorf("failed to fetch inputs for type %s: %w", input.Type(), err)
+		}
+		result[input.Name()] = f.data[input.Name()]
+	}
+	return result, warnings, nil
+}
+
+func TestScanner_FetchFailurePolicy(t *testing.T) {
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", fmt.Errorf("access denied"))
+
+	rule, err := NewRuleBuilder("pods-and-secrets", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		WithKubernetesInput("secrets", "", "v1", "secrets", "kube-system", "").
+		SetCelExpression("pods.items.size() > 0").
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+
+	tests := []struct {
+		policy         FetchFailurePolicy
+		expectedStatus CheckResultStatus
+	}{
+		{policy: "", expectedStatus: CheckResultError},
+		{policy: FetchFailurePolicyError, expectedStatus: CheckResultError},
+		{policy: FetchFailurePolicyFail, expectedStatus: CheckResultFail},
+		{policy: FetchFailurePolicyNotApplicable, expectedStatus: CheckResultNotApplicable},
+		{policy: FetchFailurePolicyEvaluate, expectedStatus: CheckResultPass},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			fetcher := &failingFetcher{
+				data:     map[string]interface{}{"pods": podList(1)},
+				errs:     map[string]error{"secrets": forbidden},
+				warnings: map[string][]string{"pods": {"input pods: served from cache"}},
+			}
+			scanner := NewScanner(fetcher, &TestLogger{t: t})
+			results, err := scanner.Scan(context.Background(), ScanConfig{
+				Rules:              []Rule{rule},
+				FetchFailurePolicy: tt.policy,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			result := results[0]
+			if result.Status != tt.expectedStatus {
+				t.Errorf("Expected %s, got %s (%s)", tt.expectedStatus, result.Status, result.ErrorMessage)
+			}
+			if !reflect.DeepEqual(result.Warnings, []string{"input pods: served from cache"}) {
+				t.Errorf("Expected fetcher warnings to be passed through, got %v", result.Warnings)
+			}
+			if len(result.FetchErrors) != 1 {
+				t.Fatalf("Expected one fetch diagnostic, got %v", result.FetchErrors)
+			}
+
+			diagnostic := result.FetchErrors[0]
+			if diagnostic.Input != "secrets" || diagnostic.InputType != InputTypeKubernetes {
+				t.Errorf("Expected the secrets input to be named, got %+v", diagnostic)
+			}
+			if diagnostic.Source != "v1/secrets in namespace kube-system" {
+				t.Errorf("Unexpected source %q", diagnostic.Source)
+			}
+			if diagnostic.Reason != "Forbidden" {
+				t.Errorf("Expected reason Forbidden, got %q", diagnostic.Reason)
+			}
+		})
+	}
+}
+
+func TestFetchErrorReason(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{err: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web"), expected: "NotFound"},
+		{err: fmt.Errorf("failed to stat path: %w", &fs.PathError{Op: "stat", Path: "/etc/x", Err: fs.ErrNotExist}), expected: "NotFound"},
+		{err: fmt.Errorf("failed to read file: %w", fs.ErrPermission), expected: "Forbidden"},
+		{err: fmt.Errorf("connection refused"), expected: ""},
+	}
+
+	for _, tt := range tests {
+		if reason := fetchErrorReason(tt.err); reason != tt.expected {
+			t.Errorf("fetchErrorReason(%v) = %q, expected %q", tt.err, reason, tt.expected)
+		}
+	}
+}
+
+func TestScanner_OptionalInputs(t *testing.T) {
+	notInstalled := &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "policy.example.com", Kind: "Policy"}}
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "tuning")
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "tuning", fmt.Errorf("access denied"))
+
+	rule, err := NewRuleBuilder("optional-inputs", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		WithKubernetesInput("policies", "policy.example.com", "v1", "policies", "", "").
+		WithKubernetesInput("tuning", "", "v1", "configmaps", "default", "tuning").
+		MarkInputOptional("policies").
+		MarkInputOptional("tuning").
+		SetCelExpression("pods.items.size() > 0 && policies.items.size() == 0 && tuning == null").
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+
+	t.Run("missing optional inputs are bound as empty", func(t *testing.T) {
+		fetcher := &failingFetcher{
+			data: map[string]interface{}{"pods": podList(1)},
+			errs: map[string]error{"policies": notInstalled, "tuning": notFound},
+		}
+		results, err := NewScanner(fetcher, &TestLogger{t: t}).Scan(context.Background(), ScanConfig{Rules: []Rule{rule}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if results[0].Status != CheckResultPass {
+			t.Errorf("Expected PASS, got %s (%s)", results[0].Status, results[0].ErrorMessage)
+		}
+		if len(results[0].Warnings) != 2 || len(results[0].FetchErrors) != 0 {
+			t.Errorf("Expected two warnings and no fetch errors, got %v and %v", results[0].Warnings, results[0].FetchErrors)
+		}
+	})
+
+	t.Run("other failures of optional inputs are fetch errors", func(t *testing.T) {
+		fetcher := &failingFetcher{
+			data: map[string]interface{}{"pods": podList(1), "policies": podList(0)},
+			errs: map[string]error{"tuning": forbidden},
+		}
+		results, err := NewScanner(fetcher, &TestLogger{t: t}).Scan(context.Background(), ScanConfig{Rules: []Rule{rule}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if results[0].Status != CheckResultError || len(results[0].FetchErrors) != 1 {
+			t.Errorf("Expected an ERROR with one fetch error, got %s %v", results[0].Status, results[0].FetchErrors)
+		}
+	})
+
+	t.Run("undeclared optional input", func(t *testing.T) {
+		_, err := NewRuleBuilder("optional-inputs", RuleTypeCEL).
+			MarkInputOptional("pods").
+			WithKubernetesInput("pods", "", "v1", "pods", "", "").
+			SetCelExpression("pods.items.size() > 0").
+			BuildCelRule()
+		if err == nil {
+			t.Fatal("Expected an error for an optional input that is not declared")
+		}
+	})
+}
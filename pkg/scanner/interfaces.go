@@ -16,7 +16,9 @@ limitations under the License.
 package scanner
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -64,9 +66,73 @@ type CelRule interface {
 	Expression() string
 }
 
+// ApplicableRule is implemented by rules that only apply when a condition on
+// their inputs holds. Rules whose condition evaluates to false are reported
+// as NOT-APPLICABLE without evaluating their expression.
+type ApplicableRule interface {
+	// ApplicabilityExpression returns the CEL condition deciding whether the
+	// rule applies; an empty expression means the rule always applies
+	ApplicabilityExpression() string
+
+	// NotApplicableReason returns the reason reported when the rule does not apply
+	NotApplicableReason() string
+}
+
+// PerObjectRule is implemented by rules evaluated once per object of a list
+// input rather than once per scan. The current object is bound to the
+// "object" variable and the result carries one finding per object.
+type PerObjectRule interface {
+	// ForEachInput returns the name of the input whose objects are iterated;
+	// an empty name evaluates the rule once
+	ForEachInput() string
+}
+
+// MissingDataRule is implemented by rules that choose how an expression
+// reading a field or index absent from their inputs is reported
+type MissingDataRule interface {
+	// MissingDataPolicy returns the policy; empty means MissingDataPolicyFail
+	MissingDataPolicy() MissingDataPolicy
+}
+
+// MessageRule is implemented by rules that render a human-readable message
+// from their inputs and variables when they fail
+type MessageRule interface {
+	// MessageExpression returns the CEL expression evaluating to the failure
+	// message; an empty expression leaves the message empty
+	MessageExpression() string
+}
+
+// CheckKind describes how a rule is checked
+type CheckKind string
+
+const (
+	// CheckKindAutomated rules are evaluated and pass or fail (the default)
+	CheckKindAutomated CheckKind = "automated"
+
+	// CheckKindManual rules cannot be automated and are reported as MANUAL
+	// without being evaluated
+	CheckKindManual CheckKind = "manual"
+
+	// CheckKindInformational rules are evaluated but reported as INFO
+	// instead of passing or failing
+	CheckKindInformational CheckKind = "informational"
+)
+
+// CheckKindRule is implemented by rules that declare how they are checked
+type CheckKindRule interface {
+	// CheckKind returns the kind; empty means CheckKindAutomated
+	CheckKind() CheckKind
+}
+
 // ScanEnvironment contains information about the environment where the scan is running
 type ScanEnvironment struct {
-	// TODO: Add environment information
+	ScanID            string    `json:"scanId,omitempty"`            // Identifies the scan; shared by all its results
+	StartTime         time.Time `json:"startTime"`                   // When the scan started
+	EndTime           time.Time `json:"endTime"`                     // When the scan ended; zero on results streamed while it runs
+	SDKVersion        string    `json:"sdkVersion,omitempty"`        // Version of the SDK module that ran the scan
+	Hostname          string    `json:"hostname,omitempty"`          // Host the scan ran on
+	KubernetesVersion string    `json:"kubernetesVersion,omitempty"` // Version of the scanned cluster, when known
+	Platform          string    `json:"platform,omitempty"`          // Distribution of the scanned cluster, such as OpenShift or EKS
 }
 
 // RuleMetadata contains metadata information for a rule
@@ -94,6 +160,15 @@ type Input interface {
 	Spec() InputSpec
 }
 
+// OptionalInput is implemented by inputs that may be absent. A missing
+// optional input, such as a resource whose CRD is not installed or a file
+// that does not exist, is bound as null (or an empty list for Kubernetes
+// lists) instead of failing the fetch.
+type OptionalInput interface {
+	// Optional reports whether the input may be absent
+	Optional() bool
+}
+
 // InputType represents the different types of inputs supported
 type InputType string
 
@@ -203,6 +278,61 @@ type CelVariable interface {
 	GroupVersionKind() schema.GroupVersionKind
 }
 
+// VariableType is the CEL type a variable value is declared and parsed as
+type VariableType string
+
+const (
+	// VariableTypeString declares the value as a string (the default)
+	VariableTypeString VariableType = "string"
+
+	// VariableTypeInt parses the value as a 64-bit integer
+	VariableTypeInt VariableType = "int"
+
+	// VariableTypeDouble parses the value as a floating point number
+	VariableTypeDouble VariableType = "double"
+
+	// VariableTypeBool parses the value as true or false
+	VariableTypeBool VariableType = "bool"
+
+	// VariableTypeDuration parses the value as a Go duration such as "90s"
+	VariableTypeDuration VariableType = "duration"
+
+	// VariableTypeList parses the value as a JSON or YAML list
+	VariableTypeList VariableType = "list"
+
+	// VariableTypeMap parses the value as a JSON or YAML object with string keys
+	VariableTypeMap VariableType = "map"
+)
+
+// TypedCelVariable is a CelVariable whose string value is declared and
+// parsed as a specific CEL type. Variables that do not implement it are
+// declared as strings.
+type TypedCelVariable interface {
+	CelVariable
+
+	// Type returns the type the value is parsed as
+	Type() VariableType
+}
+
+// ObjectReferenceVariable is a CelVariable whose value is read from a field of
+// the Kubernetes object identified by GroupVersionKind, Namespace and
+// ObjectName. Value is used as a default when the object cannot be read.
+type ObjectReferenceVariable interface {
+	CelVariable
+
+	// ObjectName returns the name of the referenced object
+	ObjectName() string
+
+	// FieldPath returns the path of the field holding the value, such as
+	// "data.maxPods" or "spec.limits['cpu.max']"
+	FieldPath() string
+}
+
+// VariableResolver reads the values of variables referencing Kubernetes objects
+type VariableResolver interface {
+	ResolveVariable(ctx context.Context, variable ObjectReferenceVariable) (string, error)
+}
+
 // InputFetcher retrieves data for different input types
 type InputFetcher interface {
 	// FetchInputs retrieves data for the specified inputs
@@ -212,6 +342,15 @@ type InputFetcher interface {
 	SupportsInputType(inputType InputType) bool
 }
 
+// ContextInputFetcher is an InputFetcher that honors cancellation and deadlines
+type ContextInputFetcher interface {
+	InputFetcher
+
+	// FetchInputsWithContext retrieves data for the specified inputs, stopping
+	// as soon as ctx is done. Warnings describe non-fatal fetch problems.
+	FetchInputsWithContext(ctx context.Context, inputs []Input, variables []CelVariable) (map[string]interface{}, []string, error)
+}
+
 // ScanLogger handles logging during CEL evaluation
 type ScanLogger interface {
 	// Debug logs debug information
@@ -240,20 +379,25 @@ type ScanResult struct {
 }
 
 // ScanStatus represents the possible outcomes of a CEL rule evaluation
-type ScanStatus string
+//
+// Deprecated: ScanStatus is an alias of CheckResultStatus, use CheckResultStatus
+type ScanStatus = CheckResultStatus
 
 const (
 	// StatusPass indicates the rule evaluation passed
-	StatusPass ScanStatus = "PASS"
+	StatusPass = CheckResultPass
 
 	// StatusFail indicates the rule evaluation failed
-	StatusFail ScanStatus = "FAIL"
+	StatusFail = CheckResultFail
 
 	// StatusError indicates an error occurred during evaluation
-	StatusError ScanStatus = "ERROR"
+	StatusError = CheckResultError
 
-	// StatusSkip indicates the rule was skipped
-	StatusSkip ScanStatus = "SKIP"
+	// StatusSkip indicates the rule was skipped. It keeps its "SKIP" value for
+	// stored results; statuses are aggregated and parsed as CheckResultSkipped.
+	//
+	// Deprecated: use CheckResultSkipped, whose value is "SKIPPED"
+	StatusSkip CheckResultStatus = "SKIP"
 )
 
 // ===== IMPLEMENTATION TYPES =====
@@ -264,6 +408,7 @@ type BaseRule struct {
 	RuleType     RuleType      `json:"type"`
 	RuleInputs   []Input       `json:"inputs"`
 	RuleMetadata *RuleMetadata `json:"metadata,omitempty"`
+	Kind         CheckKind     `json:"checkKind,omitempty"`
 }
 
 // Identifier returns the rule ID
@@ -278,28 +423,86 @@ func (r *BaseRule) Inputs() []Input { return r.RuleInputs }
 // Metadata returns the rule metadata
 func (r *BaseRule) Metadata() *RuleMetadata { return r.RuleMetadata }
 
+// CheckKind returns how the rule is checked
+func (r *BaseRule) CheckKind() CheckKind { return r.Kind }
+
 // CelRuleImpl provides a complete implementation of CelRule
 type CelRuleImpl struct {
 	BaseRule
-	CelExpr string `json:"expression"`
+	CelExpr           string            `json:"expression"`
+	ApplicabilityExpr string            `json:"applicabilityExpression,omitempty"`
+	NotApplicableMsg  string            `json:"notApplicableReason,omitempty"`
+	ForEach           string            `json:"forEach,omitempty"`
+	MissingData       MissingDataPolicy `json:"missingDataPolicy,omitempty"`
+	MessageExpr       string            `json:"messageExpression,omitempty"`
 }
 
 // Expression returns the CEL expression
 func (r *CelRuleImpl) Expression() string { return r.CelExpr }
 
+// ApplicabilityExpression returns the CEL condition deciding whether the rule applies
+func (r *CelRuleImpl) ApplicabilityExpression() string { return r.ApplicabilityExpr }
+
+// NotApplicableReason returns the reason reported when the rule does not apply
+func (r *CelRuleImpl) NotApplicableReason() string { return r.NotApplicableMsg }
+
+// ForEachInput returns the name of the input whose objects are evaluated one by one
+func (r *CelRuleImpl) ForEachInput() string { return r.ForEach }
+
+// MissingDataPolicy returns how reading absent fields is reported
+func (r *CelRuleImpl) MissingDataPolicy() MissingDataPolicy { return r.MissingData }
+
+// MessageExpression returns the CEL expression rendering the failure message
+func (r *CelRuleImpl) MessageExpression() string { return r.MessageExpr }
+
 // Content returns the CEL expression as the rule content
 func (r *CelRuleImpl) Content() interface{} { return r.CelExpr }
 
+// CelVariableImpl provides a concrete implementation of TypedCelVariable
+type CelVariableImpl struct {
+	VarName      string                  `json:"name"`
+	VarNamespace string                  `json:"namespace,omitempty"`
+	VarValue     string                  `json:"value"`
+	VarType      VariableType            `json:"type,omitempty"`
+	GVK          schema.GroupVersionKind `json:"gvk,omitempty"`
+	RefName      string                  `json:"objectName,omitempty"`
+	RefPath      string                  `json:"fieldPath,omitempty"`
+}
+
+func (v *CelVariableImpl) Name() string                              { return v.VarName }
+func (v *CelVariableImpl) Namespace() string                         { return v.VarNamespace }
+func (v *CelVariableImpl) Value() string                             { return v.VarValue }
+func (v *CelVariableImpl) GroupVersionKind() schema.GroupVersionKind { return v.GVK }
+func (v *CelVariableImpl) ObjectName() string                        { return v.RefName }
+func (v *CelVariableImpl) FieldPath() string                         { return v.RefPath }
+
+// Type returns the variable type, defaulting to string
+func (v *CelVariableImpl) Type() VariableType {
+	if v.VarType == "" {
+		return VariableTypeString
+	}
+	return v.VarType
+}
+
 // InputImpl provides a concrete implementation of the Input interface
 type InputImpl struct {
-	InputName string    `json:"name"`
-	InputType InputType `json:"type"`
-	InputSpec InputSpec `json:"spec"`
+	InputName     string    `json:"name"`
+	InputType     InputType `json:"type"`
+	InputSpec     InputSpec `json:"spec"`
+	InputOptional bool      `json:"optional,omitempty"`
 }
 
 func (i *InputImpl) Name() string    { return i.InputName }
 func (i *InputImpl) Type() InputType { return i.InputType }
 func (i *InputImpl) Spec() InputSpec { return i.InputSpec }
+func (i *InputImpl) Optional() bool  { return i.InputOptional }
+
+// optionalInput marks an Input implementation other than InputImpl as optional
+type optionalInput struct {
+	Input
+}
+
+func (i *optionalInput) Optional() bool { return true }
 
 // KubernetesInput provides a concrete implementation of KubernetesInputSpec
 type KubernetesInput struct {
@@ -384,6 +587,38 @@ func NewCelRuleWithMetadata(id, expression string, inputs []Input, metadata *Rul
 	}
 }
 
+// NewCelVariable creates a variable whose value is parsed as varType
+func NewCelVariable(name, value string, varType VariableType) TypedCelVariable {
+	return &CelVariableImpl{
+		VarName:  name,
+		VarValue: value,
+		VarType:  varType,
+	}
+}
+
+// NewObjectCelVariable creates a variable whose value is read from fieldPath
+// of the Kubernetes object gvk namespace/objectName
+func NewObjectCelVariable(name string, gvk schema.GroupVersionKind, namespace, objectName, fieldPath string, varType VariableType) TypedCelVariable {
+	return &CelVariableImpl{
+		VarName:      name,
+		VarNamespace: namespace,
+		VarType:      varType,
+		GVK:          gvk,
+		RefName:      objectName,
+		RefPath:      fieldPath,
+	}
+}
+
+// NewOptionalInput returns a copy of input that may be absent
+func NewOptionalInput(input Input) Input {
+	if impl, ok := input.(*InputImpl); ok {
+		optional := *impl
+		optional.InputOptional = true
+		return &optional
+	}
+	return &optionalInput{Input: input}
+}
+
 // NewKubernetesInput creates a Kubernetes resource input
 func NewKubernetesInput(name, group, version, resourceType, namespace, resourceName string) Input {
 	return &InputImpl{
@@ -449,7 +684,15 @@ type RuleBuilder struct {
 	inputs   []Input
 	metadata *RuleMetadata
 	// Rule-specific content
-	celExpr string
+	celExpr             string
+	applicabilityExpr   string
+	notApplicableReason string
+	forEachInput        string
+	missingDataPolicy   MissingDataPolicy
+	checkKind           CheckKind
+	messageExpr         string
+	// Inputs marked optional before being declared, reported by Build
+	unknownOptionalInputs []string
 }
 
 // NewRuleBuilder creates a new rule builder with the specified type
@@ -500,6 +743,68 @@ func (b *RuleBuilder) SetCelExpression(expression string) *RuleBuilder {
 	return b
 }
 
+// WithApplicability sets a CEL condition that must hold for the rule to
+// apply, and the reason reported when it does not
+func (b *RuleBuilder) WithApplicability(expression, notApplicableReason string) *RuleBuilder {
+	if b.ruleType != RuleTypeCEL {
+		panic(fmt.Sprintf("WithApplicability called on non-CEL rule type: %s", b.ruleType))
+	}
+	b.applicabilityExpr = expression
+	b.notApplicableReason = notApplicableReason
+	return b
+}
+
+// ForEachObject evaluates the rule once per object of the named input, which
+// must be a Kubernetes list or a file directory, binding each to "object"
+func (b *RuleBuilder) ForEachObject(inputName string) *RuleBuilder {
+	if b.ruleType != RuleTypeCEL {
+		panic(fmt.Sprintf("ForEachObject called on non-CEL rule type: %s", b.ruleType))
+	}
+	b.forEachInput = inputName
+	return b
+}
+
+// MarkInputOptional marks a previously added input as optional, so that it is
+// bound as null or an empty list when it does not exist
+func (b *RuleBuilder) MarkInputOptional(name string) *RuleBuilder {
+	for i, input := range b.inputs {
+		if input.Name() == name {
+			b.inputs[i] = NewOptionalInput(input)
+			return b
+		}
+	}
+	b.unknownOptionalInputs = append(b.unknownOptionalInputs, name)
+	return b
+}
+
+// WithMissingDataPolicy sets how the rule is reported when its expression
+// reads a field or index that is absent from its inputs
+func (b *RuleBuilder) WithMissingDataPolicy(policy MissingDataPolicy) *RuleBuilder {
+	if b.ruleType != RuleTypeCEL {
+		panic(fmt.Sprintf("WithMissingDataPolicy called on non-CEL rule type: %s", b.ruleType))
+	}
+	b.missingDataPolicy = policy
+	return b
+}
+
+// WithMessageExpression sets a CEL expression evaluating to a string that is
+// reported as the message of the rule when it fails, for example
+// `string(pods.items.size()) + " pods run as root"`
+func (b *RuleBuilder) WithMessageExpression(expression string) *RuleBuilder {
+	if b.ruleType != RuleTypeCEL {
+		panic(fmt.Sprintf("WithMessageExpression called on non-CEL rule type: %s", b.ruleType))
+	}
+	b.messageExpr = expression
+	return b
+}
+
+// WithCheckKind sets how the rule is checked. Manual rules need neither
+// inputs nor an expression.
+func (b *RuleBuilder) WithCheckKind(kind CheckKind) *RuleBuilder {
+	b.checkKind = kind
+	return b
+}
+
 // WithMetadata sets the rule metadata
 func (b *RuleBuilder) WithMetadata(metadata *RuleMetadata) *RuleBuilder {
 	b.metadata = metadata
@@ -542,26 +847,49 @@ func (b *RuleBuilder) Build() (Rule, error) {
 	if b.id == "" {
 		return nil, fmt.Errorf("rule ID is required")
 	}
-	if len(b.inputs) == 0 {
+	manual := b.checkKind == CheckKindManual
+	if len(b.inputs) == 0 && !manual {
 		return nil, fmt.Errorf("at least one input is required")
 	}
+	if len(b.unknownOptionalInputs) > 0 {
+		return nil, fmt.Errorf("optional input %s is not declared by the rule", b.unknownOptionalInputs[0])
+	}
+	if b.forEachInput != "" && !b.hasInput(b.forEachInput) {
+		return nil, fmt.Errorf("forEach input %s is not declared by the rule", b.forEachInput)
+	}
+	switch b.missingDataPolicy {
+	case "", MissingDataPolicyFail, MissingDataPolicyError, MissingDataPolicyNotApplicable:
+	default:
+		return nil, fmt.Errorf("unsupported missing data policy: %s", b.missingDataPolicy)
+	}
+	switch b.checkKind {
+	case "", CheckKindAutomated, CheckKindManual, CheckKindInformational:
+	default:
+		return nil, fmt.Errorf("unsupported check kind: %s", b.checkKind)
+	}
 
 	baseRule := BaseRule{
 		ID:           b.id,
 		RuleType:     b.ruleType,
 		RuleInputs:   b.inputs,
 		RuleMetadata: b.metadata,
+		Kind:         b.checkKind,
 	}
 
 	// Create the appropriate rule type
 	switch b.ruleType {
 	case RuleTypeCEL:
-		if b.celExpr == "" {
+		if b.celExpr == "" && !manual {
 			return nil, fmt.Errorf("CEL expression is required for CEL rules")
 		}
 		return &CelRuleImpl{
-			BaseRule: baseRule,
-			CelExpr:  b.celExpr,
+			BaseRule:          baseRule,
+			CelExpr:           b.celExpr,
+			ApplicabilityExpr: b.applicabilityExpr,
+			NotApplicableMsg:  b.notApplicableReason,
+			ForEach:           b.forEachInput,
+			MissingData:       b.missingDataPolicy,
+			MessageExpr:       b.messageExpr,
 		}, nil
 
 	case RuleTypeRego, RuleTypeJSONPath, RuleTypeCustom:
@@ -572,6 +900,16 @@ func (b *RuleBuilder) Build() (Rule, error) {
 	}
 }
 
+// hasInput reports whether an input with the given name has been added
+func (b *RuleBuilder) hasInput(name string) bool {
+	for _, input := range b.inputs {
+		if input.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
 // BuildCelRule builds and returns a CelRule (convenience method for CEL rules)
 func (b *RuleBuilder) BuildCelRule() (CelRule, error) {
 	if b.ruleType != RuleTypeCEL {
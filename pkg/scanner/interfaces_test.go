@@ -153,6 +153,28 @@ func TestRuleBuilder(t *testing.T) {
 			t.Errorf("Expected description 'Test rule for builder', got %s", metadata.Description)
 		}
 	})
+
+	t.Run("applicability condition", func(t *testing.T) {
+		rule, err := NewRuleBuilder("ingress-tls", RuleTypeCEL).
+			WithKubernetesInput("ingresses", "networking.k8s.io", "v1", "ingresses", "", "").
+			SetCelExpression("ingresses.items.all(i, has(i.spec.tls))").
+			WithApplicability("ingresses.items.size() > 0", "no Ingress objects exist").
+			BuildCelRule()
+		if err != nil {
+			t.Fatalf("Failed to build rule: %v", err)
+		}
+
+		applicable, ok := rule.(ApplicableRule)
+		if !ok {
+			t.Fatal("Expected rule to implement ApplicableRule")
+		}
+		if applicable.ApplicabilityExpression() != "ingresses.items.size() > 0" {
+			t.Errorf("Unexpected applicability expression: %s", applicable.ApplicabilityExpression())
+		}
+		if applicable.NotApplicableReason() != "no Ingress objects exist" {
+			t.Errorf("Unexpected reason: %s", applicable.NotApplicableReason())
+		}
+	})
 }
 
 // TestInputTypes validates all input type implementations
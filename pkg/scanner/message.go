@@ -0,0 +1,63 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+
+	expr "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// messageExpression returns the failure message expression of a rule, if any
+func messageExpression(rule Rule) string {
+	if message, ok := rule.(MessageRule); ok {
+		return message.MessageExpression()
+	}
+	return ""
+}
+
+// buildMessageProgram returns the program rendering the failure message of a
+// rule, or nil when it has none. A message expression that does not compile
+// leaves the message empty with a warning rather than failing the rule.
+func (s *Scanner) buildMessageProgram(rule Rule, declsList []*expr.Decl, warnings *[]string, config ScanConfig, timer *ruleTimer) *compiledProgram {
+	expression := messageExpression(rule)
+	if expression == "" {
+		return nil
+	}
+	compiled, buildErr := s.buildProgram(expression, declsList, config, timer)
+	if buildErr != nil {
+		warning := fmt.Sprintf("Invalid message expression: %v", buildErr)
+		s.logger.Warn("Rule %s: %s", rule.Identifier(), warning)
+		*warnings = append(*warnings, warning)
+		return nil
+	}
+	return compiled
+}
+
+// renderMessage evaluates a message program against the activation the rule
+// expression failed with
+func renderMessage(ctx context.Context, message *compiledProgram, activation map[string]interface{}) (string, error) {
+	out, _, err := message.program.ContextEval(ctx, activation)
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate message expression: %w", err)
+	}
+	text, ok := out.Value().(string)
+	if !ok {
+		return "", fmt.Errorf("message expression must evaluate to a string, got %s", out.Type().TypeName())
+	}
+	return text, nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func rootPods() map[string]interface{} {
+	pod := func(name string, user int64) interface{} {
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name},
+			"spec":     map[string]interface{}{"securityContext": map[string]interface{}{"runAsUser": user}},
+		}
+	}
+	return map[string]interface{}{"items": []interface{}{pod("a", 0), pod("b", 1000), pod("c", 0)}}
+}
+
+func TestScanner_MessageExpression(t *testing.T) {
+	tests := []struct {
+		name            string
+		expression      string
+		message         string
+		forEach         bool
+		expectedStatus  CheckResultStatus
+		expectedMessage string
+		expectWarning   bool
+	}{
+		{
+			name:            "failure message",
+			expression:      "pods.items.all(p, p.spec.securityContext.runAsUser != 0)",
+			message:         "string(pods.items.filter(p, p.spec.securityContext.runAsUser == 0).size()) + ' pods run as root: ' + pods.items.filter(p, p.spec.securityContext.runAsUser == 0).map(p, p.metadata.name)[0]",
+			expectedStatus:  CheckResultFail,
+			expectedMessage: "2 pods run as root: a",
+		},
+		{
+			name:           "passing rule has no message",
+			expression:     "pods.items.size() == 3",
+			message:        "'unused'",
+			expectedStatus: CheckResultPass,
+		},
+		{
+			name:           "message of the wrong type",
+			expression:     "pods.items.size() == 0",
+			message:        "pods.items.size()",
+			expectedStatus: CheckResultFail,
+			expectWarning:  true,
+		},
+		{
+			name:           "message that does not compile",
+			expression:     "pods.items.size() == 0",
+			message:        "pods.items.size(",
+			expectedStatus: CheckResultFail,
+			expectWarning:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewRuleBuilder("root-pods", RuleTypeCEL).
+				WithKubernetesInput("pods", "", "v1", "pods", "", "").
+				SetCelExpression(tt.expression).
+				WithMessageExpression(tt.message).
+				BuildCelRule()
+			if err != nil {
+				t.Fatalf("Failed to build rule: %v", err)
+			}
+
+			fetcher := &countingFetcher{data: map[string]interface{}{"pods": rootPods()}}
+			results, err := NewScanner(fetcher, &TestLogger{t: t}).Scan(context.Background(), ScanConfig{Rules: []Rule{rule}})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			result := results[0]
+			if result.Status != tt.expectedStatus || result.ErrorMessage != tt.expectedMessage {
+				t.Errorf("Expected %s %q, got %s %q", tt.expectedStatus, tt.expectedMessage, result.Status, result.ErrorMessage)
+			}
+			hasWarning := false
+			for _, warning := range result.Warnings {
+				if strings.Contains(warning, "message expression") {
+					hasWarning = true
+				}
+			}
+			if hasWarning != tt.expectWarning {
+				t.Errorf("Expected message warning=%v, got %v", tt.expectWarning, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestScanner_MessageExpressionPerObject(t *testing.T) {
+	rule, err := NewRuleBuilder("root-pod", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		ForEachObject("pods").
+		SetCelExpression("object.spec.securityContext.runAsUser != 0").
+		WithMessageExpression("'pod ' + object.metadata.name + ' runs as root'").
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+
+	fetcher := &countingFetcher{data: map[string]interface{}{"pods": rootPods()}}
+	results, err := NewScanner(fetcher, &TestLogger{t: t}).Scan(context.Background(), ScanConfig{Rules: []Rule{rule}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var messages []string
+	for _, finding := range results[0].Findings {
+		messages = append(messages, finding.Message)
+	}
+	if got := strings.Join(messages, "|"); got != "pod a runs as root||pod c runs as root" {
+		t.Errorf("Unexpected finding messages %q", got)
+	}
+}
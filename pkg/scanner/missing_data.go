@@ -0,0 +1,493 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/interpreter"
+)
+
+// MissingDataPolicy decides the result of a rule whose expression reads a
+// field or index that is not present in its inputs
+type MissingDataPolicy string
+
+const (
+	// MissingDataPolicyFail reports the rule as FAIL (the default)
+	MissingDataPolicyFail MissingDataPolicy = "fail"
+
+	// MissingDataPolicyError reports the rule as ERROR, surfacing typos in field names
+	MissingDataPolicyError MissingDataPolicy = "error"
+
+	// MissingDataPolicyNotApplicable reports the rule as NOT-APPLICABLE
+	MissingDataPolicyNotApplicable MissingDataPolicy = "not-applicable"
+)
+
+// maxMissingPathSteps bounds the expression nodes visited while looking for a
+// missing path, as comprehensions are expanded for every element
+const maxMissingPathSteps = 100000
+
+// missingDataPolicy returns the policy of a rule, defaulting to fail
+func missingDataPolicy(rule Rule) MissingDataPolicy {
+	if missingData, ok := rule.(MissingDataRule); ok && missingData.MissingDataPolicy() != "" {
+		return missingData.MissingDataPolicy()
+	}
+	return MissingDataPolicyFail
+}
+
+// missingDataStatus maps a missing-data policy to the status it reports
+func missingDataStatus(policy MissingDataPolicy) CheckResultStatus {
+	switch policy {
+	case MissingDataPolicyError:
+		return CheckResultError
+	case MissingDataPolicyNotApplicable:
+		return CheckResultNotApplicable
+	default:
+		return CheckResultFail
+	}
+}
+
+// missingDataMessage names the path an expression could not read
+func missingDataMessage(path string) string {
+	return fmt.Sprintf("Missing data: %s is not present", path)
+}
+
+// missingDataResult reports an evaluation that failed on missing data
+// according to the rule's policy
+func missingDataResult(result CheckResult, rule Rule, path string) CheckResult {
+	message := missingDataMessage(path)
+	result.Status = missingDataStatus(missingDataPolicy(rule))
+	switch result.Status {
+	case CheckResultError:
+		result.ErrorMessage = message
+	case CheckResultNotApplicable:
+		result.Reason = message
+	}
+	result.Warnings = append(result.Warnings, message)
+	return result
+}
+
+// evaluationState evaluates the program again with state tracking and returns
+// the values of the expressions that were evaluated, or nil when the program
+// cannot be traced
+func (c *compiledProgram) evaluationState(ctx context.Context, activation map[string]interface{}) interpreter.EvalState {
+	c.trackedOnce.Do(func() {
+		options := append(append([]cel.ProgramOption(nil), c.options...), cel.EvalOptions(cel.OptTrackState))
+		c.tracked, _ = c.env.Program(c.ast, options...)
+	})
+	if c.tracked == nil {
+		return nil
+	}
+	_, details, _ := c.tracked.ContextEval(ctx, activation)
+	if details == nil {
+		return nil
+	}
+	return details.State()
+}
+
+// findMissingPath looks for a field selection or index in the checked
+// expression that cannot be resolved against the activation. It is used after
+// an evaluation error to tell missing data apart from other failures, without
+// relying on the wording of CEL errors. Optional selections (?.) and has()
+// tests are allowed to be missing.
+//
+// Only the operands of &&, || and ?: recorded in state, the evaluation state
+// of the failed evaluation, are searched. Within comprehensions, whose state
+// only holds the last iteration, operands are skipped when a has() guard
+// resolved against the element decides the result without them. A nil state
+// searches every operand the guards do not rule out.
+func findMissingPath(ast *cel.Ast, activation map[string]interface{}, state interpreter.EvalState) (string, bool) {
+	if ast == nil {
+		return "", false
+	}
+	finder := &missingPathFinder{activation: activation, state: state, budget: maxMissingPathSteps}
+	return finder.find(ast.NativeRep().Expr(), nil)
+}
+
+// boundValue is a value reachable from the activation together with the path
+// it was read from. known is false for comprehension variables whose range
+// could not be resolved.
+type boundValue struct {
+	value interface{}
+	path  string
+	known bool
+}
+
+type missingPathFinder struct {
+	activation map[string]interface{}
+	state      interpreter.EvalState
+	budget     int
+}
+
+// evaluated reports whether e was evaluated, as far as the state tells
+func (f *missingPathFinder) evaluated(e celast.Expr) bool {
+	if f.state == nil {
+		return true
+	}
+	_, ok := f.state.Value(e.ID())
+	return ok
+}
+
+// find returns the first path in e that selects a field or index missing from
+// the data it is applied to
+func (f *missingPathFinder) find(e celast.Expr, scope map[string]boundValue) (string, bool) {
+	f.budget--
+	if f.budget < 0 {
+		return "", false
+	}
+
+	switch e.Kind() {
+	case celast.SelectKind:
+		sel := e.AsSelect()
+		if path, ok := f.find(sel.Operand(), scope); ok {
+			return path, true
+		}
+		if sel.IsTestOnly() {
+			return "", false
+		}
+		return f.missingStep(sel.Operand(), sel.FieldName(), scope)
+
+	case celast.CallKind:
+		call := e.AsCall()
+		args := call.Args()
+		switch call.FunctionName() {
+		case operators.Index:
+			for _, arg := range args {
+				if path, ok := f.find(arg, scope); ok {
+					return path, true
+				}
+			}
+			if key, ok := literalKey(args[1]); ok {
+				return f.missingStep(args[0], key, scope)
+			}
+			return "", false
+		case operators.OptSelect, operators.OptIndex:
+			// The final step may be absent; only its operand must resolve
+			return f.find(args[0], scope)
+		case operators.LogicalAnd, operators.LogicalOr:
+			// A false operand of && (true of ||) absorbs errors of the other
+			absorbing := call.FunctionName() == operators.LogicalOr
+			for _, arg := range args {
+				if value, known := f.truth(arg, scope); known && value == absorbing {
+					return "", false
+				}
+			}
+			for _, arg := range args {
+				if !f.evaluated(arg) {
+					continue
+				}
+				if path, ok := f.find(arg, scope); ok {
+					return path, true
+				}
+			}
+			return "", false
+		case operators.Conditional:
+			if path, ok := f.find(args[0], scope); ok {
+				return path, true
+			}
+			branches := args[1:]
+			if value, known := f.truth(args[0], scope); known {
+				if value {
+					branches = args[1:2]
+				} else {
+					branches = args[2:]
+				}
+			}
+			for _, branch := range branches {
+				if !f.evaluated(branch) {
+					continue
+				}
+				if path, ok := f.find(branch, scope); ok {
+					return path, true
+				}
+			}
+			return "", false
+		}
+		if call.IsMemberFunction() {
+			if path, ok := f.find(call.Target(), scope); ok {
+				return path, true
+			}
+		}
+		for _, arg := range args {
+			if path, ok := f.find(arg, scope); ok {
+				return path, true
+			}
+		}
+
+	case celast.ComprehensionKind:
+		if !f.evaluated(e) {
+			return "", false
+		}
+		return f.findInComprehension(e.AsComprehension(), scope)
+
+	case celast.ListKind:
+		for _, element := range e.AsList().Elements() {
+			if path, ok := f.find(element, scope); ok {
+				return path, true
+			}
+		}
+
+	case celast.MapKind:
+		for _, entry := range e.AsMap().Entries() {
+			mapEntry := entry.AsMapEntry()
+			if path, ok := f.find(mapEntry.Key(), scope); ok {
+				return path, true
+			}
+			if path, ok := f.find(mapEntry.Value(), scope); ok {
+				return path, true
+			}
+		}
+
+	case celast.StructKind:
+		for _, field := range e.AsStruct().Fields() {
+			if path, ok := f.find(field.AsStructField().Value(), scope); ok {
+				return path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// findInComprehension expands a comprehension over the elements of its range
+// when the range can be resolved, binding the iteration variable to each
+func (f *missingPathFinder) findInComprehension(c celast.ComprehensionExpr, scope map[string]boundValue) (string, bool) {
+	if path, ok := f.find(c.IterRange(), scope); ok {
+		return path, true
+	}
+	if path, ok := f.find(c.AccuInit(), scope); ok {
+		return path, true
+	}
+
+	inner := make(map[string]boundValue, len(scope)+2)
+	for name, value := range scope {
+		inner[name] = value
+	}
+	inner[c.AccuVar()] = boundValue{}
+
+	var elements []boundValue
+	if rng, ok := f.resolve(c.IterRange(), scope); ok {
+		elements = rangeElements(rng)
+	}
+	if len(elements) == 0 {
+		// Still check the body for paths not involving the iteration variable
+		elements = []boundValue{{}}
+	}
+
+	for _, element := range elements {
+		inner[c.IterVar()] = element
+		if path, ok := f.find(c.LoopCondition(), inner); ok {
+			return path, true
+		}
+		if path, ok := f.find(c.LoopStep(), inner); ok {
+			return path, true
+		}
+		if f.budget < 0 {
+			break
+		}
+	}
+	return "", false
+}
+
+// truth evaluates the guards of a condition, has() tests combined with !, &&
+// and ||, against the activation. known is false when the value depends on
+// anything else.
+func (f *missingPathFinder) truth(e celast.Expr, scope map[string]boundValue) (value bool, known bool) {
+	switch e.Kind() {
+	case celast.LiteralKind:
+		value, known = e.AsLiteral().Value().(bool)
+		return value, known
+
+	case celast.SelectKind:
+		sel := e.AsSelect()
+		if !sel.IsTestOnly() {
+			return false, false
+		}
+		parent, ok := f.resolve(sel.Operand(), scope)
+		if !ok {
+			return false, false
+		}
+		_, found, applicable := lookup(parent.value, sel.FieldName())
+		return found, applicable && parent.value != nil
+
+	case celast.CallKind:
+		call := e.AsCall()
+		args := call.Args()
+		switch call.FunctionName() {
+		case operators.LogicalNot:
+			value, known = f.truth(args[0], scope)
+			return !value, known
+		case operators.LogicalAnd, operators.LogicalOr:
+			absorbing := call.FunctionName() == operators.LogicalOr
+			known = true
+			for _, arg := range args {
+				argValue, argKnown := f.truth(arg, scope)
+				if argKnown && argValue == absorbing {
+					return absorbing, true
+				}
+				known = known && argKnown
+			}
+			return !absorbing, known
+		}
+	}
+	return false, false
+}
+
+// missingStep reports operand.key as missing when operand resolves to an
+// object without key, a list without that index, or null
+func (f *missingPathFinder) missingStep(operand celast.Expr, key interface{}, scope map[string]boundValue) (string, bool) {
+	parent, ok := f.resolve(operand, scope)
+	if !ok {
+		return "", false
+	}
+	if _, found, applicable := lookup(parent.value, key); applicable && !found {
+		return appendPath(parent.path, key), true
+	}
+	return "", false
+}
+
+// resolve evaluates identifiers, field selections and constant indexes
+// against the activation
+func (f *missingPathFinder) resolve(e celast.Expr, scope map[string]boundValue) (boundValue, bool) {
+	switch e.Kind() {
+	case celast.IdentKind:
+		name := e.AsIdent()
+		if bound, ok := scope[name]; ok {
+			return bound, bound.known
+		}
+		value, ok := f.activation[name]
+		return boundValue{value: value, path: name, known: true}, ok
+
+	case celast.SelectKind:
+		sel := e.AsSelect()
+		if sel.IsTestOnly() {
+			return boundValue{}, false
+		}
+		return f.resolveStep(sel.Operand(), sel.FieldName(), scope)
+
+	case celast.CallKind:
+		call := e.AsCall()
+		if call.FunctionName() == operators.Index {
+			if key, ok := literalKey(call.Args()[1]); ok {
+				return f.resolveStep(call.Args()[0], key, scope)
+			}
+		}
+	}
+	return boundValue{}, false
+}
+
+// resolveStep resolves operand and reads key from it
+func (f *missingPathFinder) resolveStep(operand celast.Expr, key interface{}, scope map[string]boundValue) (boundValue, bool) {
+	parent, ok := f.resolve(operand, scope)
+	if !ok {
+		return boundValue{}, false
+	}
+	value, found, _ := lookup(parent.value, key)
+	if !found {
+		return boundValue{}, false
+	}
+	return boundValue{value: value, path: appendPath(parent.path, key), known: true}, true
+}
+
+// rangeElements returns the values a comprehension over rng binds its
+// iteration variable to: list elements, or the keys of a map
+func rangeElements(rng boundValue) []boundValue {
+	value := reflect.ValueOf(rng.value)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		elements := make([]boundValue, value.Len())
+		for i := range elements {
+			elements[i] = boundValue{value: value.Index(i).Interface(), path: appendPath(rng.path, int64(i)), known: true}
+		}
+		return elements
+	case reflect.Map:
+		elements := make([]boundValue, 0, value.Len())
+		for _, key := range value.MapKeys() {
+			elements = append(elements, boundValue{value: key.Interface(), path: appendPath(rng.path, key.Interface()), known: true})
+		}
+		return elements
+	}
+	return nil
+}
+
+// lookup reads key from an object or list. applicable is false when the
+// value cannot hold key at all, as for strings or numbers; null holds nothing.
+func lookup(container interface{}, key interface{}) (value interface{}, found bool, applicable bool) {
+	if container == nil {
+		return nil, false, true
+	}
+
+	v := reflect.ValueOf(container)
+	switch v.Kind() {
+	case reflect.Map:
+		name, ok := key.(string)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return nil, false, false
+		}
+		element := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		if !element.IsValid() {
+			return nil, false, true
+		}
+		return element.Interface(), true, true
+	case reflect.Slice, reflect.Array:
+		index, ok := key.(int64)
+		if !ok {
+			return nil, false, false
+		}
+		if index < 0 || index >= int64(v.Len()) {
+			return nil, false, true
+		}
+		return v.Index(int(index)).Interface(), true, true
+	}
+	return nil, false, false
+}
+
+// literalKey returns the constant string or integer used as an index
+func literalKey(e celast.Expr) (interface{}, bool) {
+	if e.Kind() != celast.LiteralKind {
+		return nil, false
+	}
+	switch key := e.AsLiteral().Value().(type) {
+	case string:
+		return key, true
+	case int64:
+		return key, true
+	case uint64:
+		return int64(key), true
+	}
+	return nil, false
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// appendPath renders a field or index step in CEL syntax
+func appendPath(path string, key interface{}) string {
+	switch key := key.(type) {
+	case int64:
+		return fmt.Sprintf("%s[%d]", path, key)
+	case string:
+		if identifierPattern.MatchString(key) {
+			return path + "." + key
+		}
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	return fmt.Sprintf("%s[%v]", path, key)
+}
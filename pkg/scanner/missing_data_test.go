@@ -0,0 +1,165 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/checker/decls"
+	expr "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestFindMissingPath(t *testing.T) {
+	activation := map[string]interface{}{
+		"pods": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"spec": map[string]interface{}{"nodeName": "a"}},
+				map[string]interface{}{"metadata": map[string]interface{}{"name": "b"}},
+			},
+		},
+		"secret": map[string]interface{}{"data": map[string]interface{}{"tls.key": "x"}},
+	}
+
+	tests := []struct {
+		expression   string
+		expectedPath string
+	}{
+		{expression: "pods.status.phase == 'Running'", expectedPath: "pods.status"},
+		{expression: "pods.items.all(p, p.spec.nodeName != '')", expectedPath: "pods.items[1].spec"},
+		{expression: "pods.items[2].spec.nodeName != ''", expectedPath: "pods.items[2]"},
+		{expression: "secret.data['tls.crt'] != ''", expectedPath: `secret.data["tls.crt"]`},
+		{expression: "pods.items.all(p, p.?spec.?nodeName.orValue('') != '')"},
+		{expression: "pods.items.exists(p, has(p.spec))"},
+		{expression: "secret.data.size() / 0 == 1"},
+		{expression: "has(secret.data.tls) && secret.data.tls.crt != ''"},
+		{expression: "pods.items.all(p, has(p.spec) && p.spec.nodeName / 0 == 1)"},
+		{expression: "pods.items.all(p, !has(p.spec) || p.spec.nodeName / 0 == 1)"},
+		{expression: "pods.items.all(p, has(p.spec) ? p.spec.nodeName / 0 == 1 : p.metadata.labels.app == '')", expectedPath: "pods.items[1].metadata.labels"},
+		{expression: "secret.data.size() > 0 ? secret.data.size() / 0 == 1 : pods.status.phase == ''"},
+	}
+
+	declarations := []*expr.Decl{decls.NewVar("pods", decls.Dyn), decls.NewVar("secret", decls.Dyn)}
+	scanner := NewScanner(nil, &TestLogger{t: t})
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			compiled, buildErr := scanner.buildProgram(tt.expression, declarations, ScanConfig{}, nil)
+			if buildErr != nil {
+				t.Fatalf("Failed to compile: %v", buildErr)
+			}
+
+			state := compiled.evaluationState(context.Background(), activation)
+			path, missing := findMissingPath(compiled.ast, activation, state)
+			if missing != (tt.expectedPath != "") || path != tt.expectedPath {
+				t.Errorf("Expected missing path %q, got %q (missing=%v)", tt.expectedPath, path, missing)
+			}
+		})
+	}
+}
+
+func TestScanner_MissingDataPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		expression     string
+		policy         MissingDataPolicy
+		expectedStatus CheckResultStatus
+	}{
+		{
+			name:           "fail by default",
+			expression:     "pods.items.all(p, p.spec.nodeName != '')",
+			expectedStatus: CheckResultFail,
+		},
+		{
+			name:           "error",
+			expression:     "pods.items.all(p, p.spec.nodeName != '')",
+			policy:         MissingDataPolicyError,
+			expectedStatus: CheckResultError,
+		},
+		{
+			name:           "not applicable",
+			expression:     "pods.items.all(p, p.spec.nodeName != '')",
+			policy:         MissingDataPolicyNotApplicable,
+			expectedStatus: CheckResultNotApplicable,
+		},
+		{
+			name:           "optional field access",
+			expression:     "pods.items.all(p, p.?spec.?nodeName.orValue('') == '')",
+			policy:         MissingDataPolicyError,
+			expectedStatus: CheckResultPass,
+		},
+		{
+			name:           "other evaluation errors are not missing data",
+			expression:     "pods.items.size() / 0 == 1",
+			expectedStatus: CheckResultError,
+		},
+		{
+			name:           "branches that did not run are not blamed",
+			expression:     "pods.items.size() > 0 ? pods.items.size() / 0 == 1 : pods.status.phase == ''",
+			expectedStatus: CheckResultError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewRuleBuilder("pods-scheduled", RuleTypeCEL).
+				WithKubernetesInput("pods", "", "v1", "pods", "", "").
+				SetCelExpression(tt.expression).
+				WithMissingDataPolicy(tt.policy).
+				BuildCelRule()
+			if err != nil {
+				t.Fatalf("Failed to build rule: %v", err)
+			}
+
+			fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(1)}}
+			scanner := NewScanner(fetcher, &TestLogger{t: t})
+			results, err := scanner.Scan(context.Background(), ScanConfig{Rules: []Rule{rule}})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			result := results[0]
+			if result.Status != tt.expectedStatus {
+				t.Fatalf("Expected %s, got %s (%s)", tt.expectedStatus, result.Status, result.ErrorMessage)
+			}
+			if tt.policy == MissingDataPolicyNotApplicable && !strings.Contains(result.Reason, "pods.items[0].spec") {
+				t.Errorf("Expected the reason to name the missing path, got %q", result.Reason)
+			}
+			if tt.policy == MissingDataPolicyError && result.Status == CheckResultError && result.ErrorMessage != "Missing data: pods.items[0].spec is not present" {
+				t.Errorf("Unexpected error message %q", result.ErrorMessage)
+			}
+		})
+	}
+}
+
+func TestRuleBuilder_InvalidMissingDataPolicy(t *testing.T) {
+	_, err := NewRuleBuilder("pods", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		SetCelExpression("pods.items.size() > 0").
+		WithMissingDataPolicy("ignore").
+		BuildCelRule()
+	if err == nil {
+		t.Fatal("Expected an error for an unsupported missing data policy")
+	}
+}
+
+func TestRuleValidator_OptionalSyntax(t *testing.T) {
+	declarations := []*expr.Decl{decls.NewVar("pods", decls.Dyn)}
+	issues := NewRuleValidator(&MockLogger{}).ValidateCELExpressionWithInputs("pods.?spec.?replicas.orValue(1) > 0 && pods[?'status'].hasValue()", declarations)
+	if len(issues) > 0 {
+		t.Errorf("Expected optional syntax to validate, got %v", issues)
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ObjectVariableName is the CEL variable bound to the current item when a
+// rule is evaluated once per object
+const ObjectVariableName = "object"
+
+// ObjectFinding is the outcome of a per-object rule for a single object
+type ObjectFinding struct {
+	APIVersion  string            `json:"apiVersion,omitempty"`
+	Kind        string            `json:"kind,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Name        string            `json:"name"`
+	Status      CheckResultStatus `json:"status"`
+	Message     string            `json:"message,omitempty"`
+	Explanation *Explanation      `json:"explanation,omitempty"` // Why the expression was false for the object
+}
+
+// evaluatePerObject evaluates a rule once for every object of its forEach
+// input and derives the overall status from the findings. The message
+// program, if any, describes each failing object.
+func (s *Scanner) evaluatePerObject(ctx context.Context, compiled, message *compiledProgram, activation map[string]interface{}, rule Rule, inputName string, warnings []string, config ScanConfig) CheckResult {
+	input := findInput(rule, inputName)
+	if input == nil {
+		errorMsg := fmt.Sprintf("forEach input %q is not declared by the rule", inputName)
+		return s.createErrorResultWithContext(rule, warnings, errorMsg, nil, config.Variables)
+	}
+	data, ok := activation[inputName]
+	if !ok {
+		errorMsg := fmt.Sprintf("No data available for forEach input %q", inputName)
+		return s.createErrorResultWithContext(rule, warnings, errorMsg, nil, config.Variables)
+	}
+
+	objects, err := objectsOf(input, data)
+	if err != nil {
+		return s.createErrorResultWithContext(rule, warnings, err.Error(), nil, config.Variables)
+	}
+	if len(objects) == 0 {
+		return CheckResult{
+			ID:       rule.Identifier(),
+			Status:   CheckResultNotApplicable,
+			Metadata: CheckResultMetadata{},
+			Warnings: warnings,
+			Reason:   fmt.Sprintf("no objects to evaluate in input %s", inputName),
+		}
+	}
+
+	findings := make([]ObjectFinding, 0, len(objects))
+	objectActivation := make(map[string]interface{}, len(activation)+1)
+	for name, value := range activation {
+		objectActivation[name] = value
+	}
+
+	for _, object := range objects {
+		finding := objectIdentity(input, object)
+		objectActivation[ObjectVariableName] = object.value
+
+		out, details, err := compiled.program.ContextEval(ctx, objectActivation)
+		switch {
+		case err != nil:
+			if errorMsg, limited := evaluationLimitError(ctx, err, details, config); limited {
+				// Remaining objects would hit the same limit
+				return s.createErrorResultWithContext(rule, warnings, errorMsg, nil, config.Variables)
+			}
+			if path, missing := findMissingPath(compiled.ast, objectActivation, compiled.evaluationState(ctx, objectActivation)); missing {
+				finding.Status = missingDataStatus(missingDataPolicy(rule))
+				finding.Message = missingDataMessage(path)
+			} else {
+				finding.Status = CheckResultError
+				finding.Message = fmt.Sprintf("Failed to evaluate CEL expression: %v", err)
+			}
+		case out.Value() == false:
+			finding.Status = CheckResultFail
+			finding.Explanation = s.explanationFor(ctx, compiled, rule, objectActivation, config)
+			if message != nil {
+				text, err := renderMessage(ctx, message, objectActivation)
+				if err != nil {
+					text = err.Error()
+				}
+				finding.Message = text
+			}
+		default:
+			finding.Status = CheckResultPass
+		}
+		findings = append(findings, finding)
+	}
+
+	result := CheckResult{
+		ID:       rule.Identifier(),
+		Status:   aggregateFindings(findings),
+		Metadata: CheckResultMetadata{},
+		Warnings: warnings,
+		Findings: findings,
+	}
+	if result.Status == CheckResultError {
+		result.ErrorMessage = fmt.Sprintf("%d of %d objects could not be evaluated", countFindings(findings, CheckResultError), len(findings))
+	}
+	s.logger.Info("%s: %s (%d objects, %d failed)", rule.Identifier(), result.Status, len(findings), countFindings(findings, CheckResultFail))
+	return result
+}
+
+// aggregateFindings derives the status of a rule from its findings following
+// AggregateStatuses: any failing object fails the rule, otherwise any error
+// makes it an error. The rule is not applicable when none of its objects is.
+func aggregateFindings(findings []ObjectFinding) CheckResultStatus {
+	statuses := make([]CheckResultStatus, len(findings))
+	for i, finding := range findings {
+		statuses[i] = finding.Status
+	}
+	if status := AggregateStatuses(statuses); status != "" {
+		return status
+	}
+	return CheckResultPass
+}
+
+// countFindings returns the number of findings with the given status
+func countFindings(findings []ObjectFinding, status CheckResultStatus) int {
+	count := 0
+	for _, finding := range findings {
+		if finding.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// findInput returns the input of a rule bound to name
+func findInput(rule Rule, name string) Input {
+	for _, input := range rule.Inputs() {
+		if input.Name() == name {
+			return input
+		}
+	}
+	return nil
+}
+
+// inputObject is a single item of a list input together with its key in a
+// directory listing
+type inputObject struct {
+	path  string
+	value interface{}
+}
+
+// objectsOf splits fetched input data into the objects a per-object rule is
+// evaluated against. Kubernetes lists yield their items and single objects
+// themselves; file directory listings yield one object per file, exposing
+// its relative path and content (plus metadata when permissions are checked).
+func objectsOf(input Input, data interface{}) ([]inputObject, error) {
+	if items, ok := data.([]interface{}); ok {
+		return listObjects(items), nil
+	}
+
+	object, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("input %s is not a list of objects (got %T)", input.Name(), data)
+	}
+
+	switch input.Type() {
+	case InputTypeKubernetes:
+		if items, ok := object["items"].([]interface{}); ok {
+			return listObjects(items), nil
+		}
+		return []inputObject{{value: object}}, nil
+
+	case InputTypeFile:
+		paths := make([]string, 0, len(object))
+		for path := range object {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		checkPermissions := false
+		if spec, ok := input.Spec().(FileInputSpec); ok {
+			checkPermissions = spec.CheckPermissions()
+		}
+
+		objects := make([]inputObject, 0, len(paths))
+		for _, path := range paths {
+			file := map[string]interface{}{"path": path, "content": object[path]}
+			if metadata, ok := object[path].(map[string]interface{}); ok && checkPermissions {
+				for key, value := range metadata {
+					file[key] = value
+				}
+			}
+			objects = append(objects, inputObject{path: path, value: file})
+		}
+		return objects, nil
+
+	default:
+		return []inputObject{{value: object}}, nil
+	}
+}
+
+// listObjects wraps the items of a list
+func listObjects(items []interface{}) []inputObject {
+	objects := make([]inputObject, 0, len(items))
+	for _, item := range items {
+		objects = append(objects, inputObject{value: item})
+	}
+	return objects
+}
+
+// objectIdentity returns a finding identifying an object by its Kubernetes
+// type and metadata, or by file path for file inputs
+func objectIdentity(input Input, object inputObject) ObjectFinding {
+	if object.path != "" {
+		return ObjectFinding{Kind: "File", Name: object.path}
+	}
+
+	finding := ObjectFinding{}
+	fields, ok := object.value.(map[string]interface{})
+	if !ok {
+		return finding
+	}
+	finding.APIVersion, _ = fields["apiVersion"].(string)
+	finding.Kind, _ = fields["kind"].(string)
+	if metadata, ok := fields["metadata"].(map[string]interface{}); ok {
+		finding.Namespace, _ = metadata["namespace"].(string)
+		finding.Name, _ = metadata["name"].(string)
+	}
+	return finding
+}
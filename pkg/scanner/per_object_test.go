@@ -0,0 +1,179 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func deployment(namespace, name string, replicas int) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": namespace, "name": name},
+		"spec":       map[string]interface{}{"replicas": replicas},
+	}
+}
+
+func TestScanner_PerObjectKubernetes(t *testing.T) {
+	rule, err := NewRuleBuilder("deployments-replicas", RuleTypeCEL).
+		WithKubernetesInput("deployments", "apps", "v1", "deployments", "", "").
+		SetCelExpression("object.spec.replicas >= 2").
+		ForEachObject("deployments").
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		items            []interface{}
+		expectedStatus   CheckResultStatus
+		expectedFindings []ObjectFinding
+	}{
+		{
+			name: "one failing object fails the rule",
+			items: []interface{}{
+				deployment("web", "frontend", 3),
+				deployment("web", "backend", 1),
+			},
+			expectedStatus: CheckResultFail,
+			expectedFindings: []ObjectFinding{
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "web", Name: "frontend", Status: CheckResultPass},
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "web", Name: "backend", Status: CheckResultFail},
+			},
+		},
+		{
+			name:           "all objects pass",
+			items:          []interface{}{deployment("web", "frontend", 2)},
+			expectedStatus: CheckResultPass,
+			expectedFindings: []ObjectFinding{
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "web", Name: "frontend", Status: CheckResultPass},
+			},
+		},
+		{
+			name:           "no objects",
+			items:          []interface{}{},
+			expectedStatus: CheckResultNotApplicable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &countingFetcher{data: map[string]interface{}{
+				"deployments": map[string]interface{}{"items": tt.items},
+			}}
+			scanner := NewScanner(fetcher, &TestLogger{t: t})
+
+			results, err := scanner.Scan(context.Background(), ScanConfig{Rules: []Rule{rule}})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if results[0].Status != tt.expectedStatus {
+				t.Errorf("Expected %s, got %s (%s)", tt.expectedStatus, results[0].Status, results[0].ErrorMessage)
+			}
+			if !reflect.DeepEqual(results[0].Findings, tt.expectedFindings) {
+				t.Errorf("Expected findings %+v, got %+v", tt.expectedFindings, results[0].Findings)
+			}
+		})
+	}
+}
+
+func TestScanner_PerObjectFiles(t *testing.T) {
+	rule, err := NewRuleBuilder("configs-debug-off", RuleTypeCEL).
+		WithFileInput("configs", "/etc/app", "yaml", false, false).
+		SetCelExpression("object.content.debug == false").
+		ForEachObject("configs").
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+
+	// Directory results are keyed by path relative to the directory
+	fetcher := &countingFetcher{data: map[string]interface{}{
+		"configs": map[string]interface{}{
+			"b.yaml": map[string]interface{}{"debug": true},
+			"a.yaml": map[string]interface{}{"debug": false},
+			"c.yaml": map[string]interface{}{"verbose": true},
+		},
+	}}
+	scanner := NewScanner(fetcher, &TestLogger{t: t})
+
+	results, err := scanner.Scan(context.Background(), ScanConfig{Rules: []Rule{rule}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].Status != CheckResultFail {
+		t.Errorf("Expected FAIL, got %s", results[0].Status)
+	}
+	expectedStatuses := map[string]CheckResultStatus{
+		"a.yaml": CheckResultPass,
+		"b.yaml": CheckResultFail,
+		"c.yaml": CheckResultFail, // missing key
+	}
+	if len(results[0].Findings) != len(expectedStatuses) {
+		t.Fatalf("Expected %d findings, got %+v", len(expectedStatuses), results[0].Findings)
+	}
+	for i, finding := range results[0].Findings {
+		if finding.Kind != "File" {
+			t.Errorf("Finding %d: expected kind File, got %s", i, finding.Kind)
+		}
+		if finding.Status != expectedStatuses[finding.Name] {
+			t.Errorf("Finding %s: expected %s, got %s", finding.Name, expectedStatuses[finding.Name], finding.Status)
+		}
+	}
+	if results[0].Findings[0].Name != "a.yaml" {
+		t.Errorf("Expected findings in path order, got %+v", results[0].Findings)
+	}
+}
+
+func TestRuleBuilder_ForEachObjectUnknownInput(t *testing.T) {
+	_, err := NewRuleBuilder("unknown-input", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		SetCelExpression("object.metadata.name != ''").
+		ForEachObject("deployments").
+		BuildCelRule()
+	if err == nil {
+		t.Fatal("Expected an error for an undeclared forEach input")
+	}
+}
+
+func TestAggregateFindings(t *testing.T) {
+	tests := []struct {
+		statuses []CheckResultStatus
+		expected CheckResultStatus
+	}{
+		{[]CheckResultStatus{CheckResultPass, CheckResultPass}, CheckResultPass},
+		{[]CheckResultStatus{CheckResultPass, CheckResultError}, CheckResultError},
+		{[]CheckResultStatus{CheckResultError, CheckResultFail}, CheckResultFail},
+		{[]CheckResultStatus{CheckResultNotApplicable, CheckResultPass}, CheckResultPass},
+		{[]CheckResultStatus{CheckResultNotApplicable, CheckResultNotApplicable}, CheckResultNotApplicable},
+	}
+
+	for _, tt := range tests {
+		var findings []ObjectFinding
+		for _, status := range tt.statuses {
+			findings = append(findings, ObjectFinding{Status: status})
+		}
+		if status := aggregateFindings(findings); status != tt.expected {
+			t.Errorf("%v: expected %s, got %s", tt.statuses, tt.expected, status)
+		}
+	}
+}
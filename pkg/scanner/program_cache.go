@@ -0,0 +1,155 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"container/list"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	expr "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// DefaultProgramCacheSize is the number of compiled programs kept by a scanner by default
+const DefaultProgramCacheSize = 512
+
+// ProgramCacheStats reports the effectiveness of a ProgramCache
+type ProgramCacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size"`
+}
+
+// ProgramCache is a bounded, least-recently-used cache of compiled CEL
+// programs. Entries are keyed by the expression, the declarations it was
+// checked against and the environment options in use, so a cache can be
+// shared between scans and scanners. It is safe for concurrent use.
+type ProgramCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	stats    ProgramCacheStats
+}
+
+// compiledProgram is a checked expression together with its environment and program
+type compiledProgram struct {
+	key     string
+	env     *cel.Env
+	ast     *cel.Ast
+	program cel.Program
+	options []cel.ProgramOption
+
+	// Program tracking the value of every evaluated expression, built on
+	// first use to attribute evaluation errors
+	trackedOnce sync.Once
+	tracked     cel.Program
+
+	// Programs of the parts of a traced expression, by part and declared
+	// variables, compiled on demand by explanations
+	partsMu sync.Mutex
+	parts   map[string]cel.Program
+}
+
+// NewProgramCache creates a program cache holding at most capacity programs.
+// A capacity of zero or less uses DefaultProgramCacheSize.
+func NewProgramCache(capacity int) *ProgramCache {
+	if capacity <= 0 {
+		capacity = DefaultProgramCacheSize
+	}
+	return &ProgramCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// get returns the cached program for key, marking it as recently used
+func (c *ProgramCache) get(key string) (*compiledProgram, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*compiledProgram), true
+}
+
+// add stores a program, evicting the least recently used entries over capacity
+func (c *ProgramCache) add(program *compiledProgram) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[program.key]; ok {
+		element.Value = program
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[program.key] = c.order.PushFront(program)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*compiledProgram).key)
+		c.stats.Evictions++
+	}
+}
+
+// Purge removes every cached program
+func (c *ProgramCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// Len returns the number of cached programs
+func (c *ProgramCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Stats returns the hit, miss and eviction counters of the cache
+func (c *ProgramCache) Stats() ProgramCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.order.Len()
+	return stats
+}
+
+// programCacheKey builds the cache key of an expression checked against the
+// given declarations in the environment identified by envKey and planned with
+// the program options identified by optionsKey
+func programCacheKey(envKey string, optionsKey string, expression string, declsList []*expr.Decl) string {
+	declKeys := make([]string, 0, len(declsList))
+	for _, decl := range declsList {
+		declKeys = append(declKeys, decl.GetName()+":"+decl.GetIdent().GetType().String())
+	}
+	sort.Strings(declKeys)
+
+	return strings.Join([]string{envKey, optionsKey, strings.Join(declKeys, ","), expression}, "\x00")
+}
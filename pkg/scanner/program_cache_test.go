@@ -0,0 +1,157 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	expr "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+func TestProgramCacheKey(t *testing.T) {
+	podsDyn := []*expr.Decl{decls.NewVar("pods", decls.Dyn), decls.NewVar("name", decls.String)}
+	reordered := []*expr.Decl{decls.NewVar("name", decls.String), decls.NewVar("pods", decls.Dyn)}
+	retyped := []*expr.Decl{decls.NewVar("pods", decls.Dyn), decls.NewVar("name", decls.Int)}
+
+	base := programCacheKey("env-1", "", "pods.items.size() > 0", podsDyn)
+
+	if key := programCacheKey("env-1", "", "pods.items.size() > 0", reordered); key != base {
+		t.Errorf("Expected declaration order not to change the key")
+	}
+	if key := programCacheKey("env-1", "", "pods.items.size() > 0", retyped); key == base {
+		t.Errorf("Expected declaration types to change the key")
+	}
+	if key := programCacheKey("env-2", "", "pods.items.size() > 0", podsDyn); key == base {
+		t.Errorf("Expected environment options to change the key")
+	}
+	if key := programCacheKey("env-1", "", "pods.items.size() > 1", podsDyn); key == base {
+		t.Errorf("Expected the expression to change the key")
+	}
+	if key := programCacheKey("env-1", "cost=100", "pods.items.size() > 0", podsDyn); key == base {
+		t.Errorf("Expected program options to change the key")
+	}
+}
+
+func TestProgramCache_Eviction(t *testing.T) {
+	cache := NewProgramCache(2)
+
+	cache.add(&compiledProgram{key: "a"})
+	cache.add(&compiledProgram{key: "b"})
+
+	// Touch "a" so that "b" becomes the least recently used entry
+	if _, ok := cache.get("a"); !ok {
+		t.Fatalf("Expected a to be cached")
+	}
+	cache.add(&compiledProgram{key: "c"})
+
+	if _, ok := cache.get("b"); ok {
+		t.Errorf("Expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.get(key); !ok {
+			t.Errorf("Expected %s to be cached", key)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.Size != 2 || stats.Evictions != 1 || stats.Hits != 3 || stats.Misses != 1 {
+		t.Errorf("Unexpected cache stats: %+v", stats)
+	}
+
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Errorf("Expected empty cache after purge, got %d entries", cache.Len())
+	}
+}
+
+func TestScanner_ProgramCacheReuse(t *testing.T) {
+	rule, err := NewRuleBuilder("pods-exist", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		SetCelExpression("pods.items.size() > 0").
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+
+	fetcher := &countingFetcher{
+		data: map[string]interface{}{
+			"pods": map[string]interface{}{"items": []interface{}{map[string]interface{}{}}},
+		},
+	}
+	scanner := NewScanner(fetcher, &TestLogger{t: t})
+	config := ScanConfig{Rules: []Rule{rule}}
+
+	for i := 0; i < 3; i++ {
+		results, err := scanner.Scan(context.Background(), config)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if results[0].Status != CheckResultPass {
+			t.Fatalf("Expected PASS, got %s (%v)", results[0].Status, results[0].Warnings)
+		}
+	}
+
+	stats := scanner.ProgramCache().Stats()
+	if stats.Misses != 1 || stats.Hits != 2 {
+		t.Errorf("Expected 1 compilation and 2 reuses, got %+v", stats)
+	}
+}
+
+func TestScanner_WithCelEnvOptionsInvalidatesCache(t *testing.T) {
+	rule, err := NewRuleBuilder("shout", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		SetCelExpression(`shout("a") == "A"`).
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+
+	fetcher := &countingFetcher{
+		data: map[string]interface{}{"pods": map[string]interface{}{"items": []interface{}{}}},
+	}
+	scanner := NewScanner(fetcher, &TestLogger{t: t})
+	config := ScanConfig{Rules: []Rule{rule}}
+
+	results, _ := scanner.Scan(context.Background(), config)
+	if results[0].Status != CheckResultError {
+		t.Fatalf("Expected ERROR without the shout function, got %s", results[0].Status)
+	}
+
+	// Programs compiled by another scanner sharing the cache are kept
+	scanner.ProgramCache().add(&compiledProgram{key: "shared"})
+	cached := scanner.ProgramCache().Len()
+
+	scanner.WithCelEnvOptions(cel.Function("shout",
+		cel.Overload("shout_string", []*cel.Type{cel.StringType}, cel.StringType,
+			cel.UnaryBinding(func(val ref.Val) ref.Val {
+				return types.String(strings.ToUpper(string(val.(types.String))))
+			}))))
+
+	if scanner.ProgramCache().Len() != cached {
+		t.Errorf("Expected the shared program cache to be kept when environment options change")
+	}
+
+	results, _ = scanner.Scan(context.Background(), config)
+	if results[0].Status != CheckResultPass {
+		t.Errorf("Expected PASS with the shout function, got %s (%v)", results[0].Status, results[0].Warnings)
+	}
+}
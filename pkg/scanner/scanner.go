@@ -23,11 +23,15 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/checker/decls"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
 	expr "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -40,7 +44,13 @@ type CheckResult struct {
 	Status       CheckResultStatus   `json:"status"`
 	Metadata     CheckResultMetadata `json:"metadata"`
 	Warnings     []string            `json:"warnings"`
-	ErrorMessage string              `json:"errorMessage"`
+	ErrorMessage string              `json:"errorMessage"`          // Why the rule errored, or the rendered message of a failing rule
+	Reason       string              `json:"reason,omitempty"`      // Why the rule was not applicable
+	Findings     []ObjectFinding     `json:"findings,omitempty"`    // Per-object outcomes of rules evaluated for each object
+	FetchErrors  []FetchDiagnostic   `json:"fetchErrors,omitempty"` // Inputs that could not be fetched
+	Cached       bool                `json:"cached,omitempty"`      // Reused from a previous scan since nothing the rule depends on changed
+	Evidence     []InputEvidence     `json:"evidence,omitempty"`    // Data the rule was evaluated with, when ScanConfig.Evidence is set
+	Explanation  *Explanation        `json:"explanation,omitempty"` // Why the expression was false, when ScanConfig.Explain is set
 }
 
 // CheckResultStatus represents the status of a check result
@@ -51,6 +61,11 @@ const (
 	CheckResultFail          CheckResultStatus = "FAIL"
 	CheckResultError         CheckResultStatus = "ERROR"
 	CheckResultNotApplicable CheckResultStatus = "NOT-APPLICABLE"
+	CheckResultManual        CheckResultStatus = "MANUAL"       // The control cannot be checked automatically
+	CheckResultInfo          CheckResultStatus = "INFO"         // The rule is informational and neither passes nor fails
+	CheckResultInconsistent  CheckResultStatus = "INCONSISTENT" // Results of the rule disagree across nodes
+	CheckResultSkipped       CheckResultStatus = "SKIPPED"      // The rule was deselected from the scan
+	CheckResultWaived        CheckResultStatus = "WAIVED"       // The rule failed but the risk has been accepted
 )
 
 // ResourceFetcher defines the interface for fetching resources using the new API
@@ -60,8 +75,41 @@ type ResourceFetcher interface {
 
 // Scanner provides CEL-based compliance scanning functionality
 type Scanner struct {
-	resourceFetcher ResourceFetcher
-	logger          Logger
+	resourceFetcher  ResourceFetcher
+	logger           Logger
+	variableResolver VariableResolver
+	stateStore       StateStore
+
+	// Compiled programs are reused across rules and scans. envKey identifies
+	// the environment options the cached programs were built with, within
+	// this process; envFingerprint identifies them across processes.
+	programCache   *ProgramCache
+	envOptions     []cel.EnvOption
+	envKey         string
+	envFingerprint string
+
+	mu                sync.Mutex
+	lastSnapshotStats InputSnapshotStats
+	lastEnvironment   ScanEnvironment
+	lastSummary       ScanSummary
+}
+
+// scanRun holds the state shared by all rules of a single Scan call
+type scanRun struct {
+	config      ScanConfig
+	snapshot    *inputSnapshot
+	sink        *serializedSink
+	environment ScanEnvironment
+
+	// rules holds the statistics of each rule, by index in config.Rules
+	rules   []RuleStatistics
+	summary ScanSummary
+
+	// variableWarnings holds resolution failures keyed by variable name
+	variableWarnings map[string]string
+
+	// reused counts the results taken from the state store
+	reused atomic.Int64
 }
 
 // Logger defines the interface for logging
@@ -96,17 +144,83 @@ func NewScanner(resourceFetcher ResourceFetcher, logger Logger) *Scanner {
 	if logger == nil {
 		logger = DefaultLogger{}
 	}
+	// Fetchers able to read Kubernetes objects also resolve variables
+	resolver, _ := resourceFetcher.(VariableResolver)
 	return &Scanner{
-		resourceFetcher: resourceFetcher,
-		logger:          logger,
+		resourceFetcher:  resourceFetcher,
+		logger:           logger,
+		variableResolver: resolver,
+		programCache:     NewProgramCache(DefaultProgramCacheSize),
+		envKey:           nextEnvKey(),
 	}
 }
 
+// envGeneration numbers CEL environment configurations so that programs
+// compiled with different environment options never share a cache key
+var envGeneration atomic.Uint64
+
+func nextEnvKey() string {
+	return fmt.Sprintf("env-%d", envGeneration.Add(1))
+}
+
+// WithProgramCache sets the cache used for compiled CEL programs, allowing a
+// cache to be shared between scanners. A nil cache disables caching.
+func (s *Scanner) WithProgramCache(cache *ProgramCache) *Scanner {
+	s.programCache = cache
+	return s
+}
+
+// WithVariableResolver sets the resolver used for variables referencing
+// Kubernetes objects. By default the resource fetcher is used when it
+// implements VariableResolver.
+func (s *Scanner) WithVariableResolver(resolver VariableResolver) *Scanner {
+	s.variableResolver = resolver
+	return s
+}
+
+// WithStateStore enables incremental scans: the result of every rule is
+// recorded in store, and rules whose expression, variables and input data are
+// unchanged since the recorded result reuse it instead of being evaluated.
+// A nil store disables incremental scans.
+func (s *Scanner) WithStateStore(store StateStore) *Scanner {
+	s.stateStore = store
+	return s
+}
+
+// WithCelEnvOptions adds CEL environment options (for example extension
+// libraries) to the scanner environment. Programs compiled with the previous
+// options are no longer used; they stay in the program cache, which may be
+// shared with other scanners, until evicted.
+func (s *Scanner) WithCelEnvOptions(opts ...cel.EnvOption) *Scanner {
+	s.envOptions = opts
+	s.envKey = nextEnvKey()
+	s.envFingerprint = celEnvFingerprint(opts)
+	return s
+}
+
+// ProgramCache returns the cache used for compiled CEL programs
+func (s *Scanner) ProgramCache() *ProgramCache {
+	return s.programCache
+}
+
 // ValidateRule validates a rule without executing it
 // This method allows SDK users to validate CEL expressions before deployment
 func (s *Scanner) ValidateRule(rule Rule) ValidationResult {
-	validator := NewRuleValidator(s.logger)
-	return validator.ValidateRule(rule)
+	return s.ValidateRuleWithVariables(rule, nil)
+}
+
+// ValidateRuleWithVariables validates a rule against the variables it will be scanned with
+func (s *Scanner) ValidateRuleWithVariables(rule Rule, variables []CelVariable) ValidationResult {
+	return s.validateRuleForScan(rule, ScanConfig{Variables: variables})
+}
+
+// validateRuleForScan validates a rule against the variables and cost limit of config
+func (s *Scanner) validateRuleForScan(rule Rule, config ScanConfig) ValidationResult {
+	validator := NewRuleValidator(s.logger).WithCelEnvOptions(s.envOptions...)
+	if config.CostLimit > 0 {
+		validator = validator.WithCostLimit(config.CostLimit)
+	}
+	return validator.ValidateRuleWithVariables(rule, config.Variables)
 }
 
 // ValidateCELExpression validates a CEL expression with given inputs
@@ -122,7 +236,7 @@ func (s *Scanner) ValidateAllRules(config ScanConfig) map[string]ValidationResul
 
 	for _, rule := range config.Rules {
 		s.logger.Debug("Validating rule: %s (type: %s)", rule.Identifier(), rule.Type())
-		result := s.ValidateRule(rule)
+		result := s.validateRuleForScan(rule, config)
 		results[rule.Identifier()] = result
 
 		if !result.Valid {
@@ -153,130 +267,531 @@ func (s *Scanner) PreflightCheck(config ScanConfig) (bool, map[string]Validation
 
 // ScanConfig holds configuration for scanning
 type ScanConfig struct {
-	Rules                   []Rule        `json:"rules"`
-	Variables               []CelVariable `json:"variables"`
-	ApiResourcePath         string        `json:"apiResourcePath"`
-	EnableDebugLogging      bool          `json:"enableDebugLogging"`
-	ValidateBeforeExecution bool          `json:"validateBeforeExecution"` // Validate rules before running them
+	Rules                   []Rule             `json:"rules"`
+	Variables               []CelVariable      `json:"variables"`
+	ApiResourcePath         string             `json:"apiResourcePath"`
+	EnableDebugLogging      bool               `json:"enableDebugLogging"`
+	ValidateBeforeExecution bool               `json:"validateBeforeExecution"` // Validate rules before running them
+	MaxConcurrency          int                `json:"maxConcurrency"`          // Maximum number of rules processed in parallel (defaults to 1)
+	ScanTimeout             time.Duration      `json:"scanTimeout"`             // Deadline for the whole scan (0 means no deadline)
+	RuleTimeout             time.Duration      `json:"ruleTimeout"`             // Deadline for each rule, including input fetching (0 means no deadline)
+	CostLimit               uint64             `json:"costLimit"`               // Maximum CEL runtime cost of a single rule evaluation (0 means unlimited)
+	EvalTimeout             time.Duration      `json:"evalTimeout"`             // Wall-clock limit for evaluating a single rule expression (0 means unlimited)
+	FetchFailurePolicy      FetchFailurePolicy `json:"fetchFailurePolicy"`      // Result of rules with inputs that could not be fetched (defaults to error)
+	SkipRules               []string           `json:"skipRules,omitempty"`     // IDs of deselected rules, reported as SKIPPED without being evaluated
+	Waivers                 map[string]string  `json:"waivers,omitempty"`       // Justifications keyed by rule ID; failures of these rules are reported as WAIVED
+	Evidence                *EvidenceOptions   `json:"evidence,omitempty"`      // Capture the data each rule was evaluated with (nil disables capture)
+	Explain                 *ExplainOptions    `json:"explain,omitempty"`       // Explain which parts of failing expressions were false (nil disables explanations)
+	ScanID                  string             `json:"scanId,omitempty"`        // Identifier recorded in the environment of every result (generated when empty)
 }
 
-// Scan executes compliance checks for the given rules and returns results
+// Scan executes compliance checks for the given rules and returns results.
+// Rules that time out or are not reached before ctx is done are reported as
+// ERROR; the context error is returned only when the caller's ctx is done.
+// Every result carries the same scan environment in its metadata.
 func (s *Scanner) Scan(ctx context.Context, config ScanConfig) ([]CheckResult, error) {
-	results := []CheckResult{}
+	results, _, err := s.ScanWithSummary(ctx, config)
+	return results, err
+}
 
-	for _, rule := range config.Rules {
-		s.logger.Debug("Processing rule: %s (type: %s)", rule.Identifier(), rule.Type())
-
-		// Validate rule before processing (optional but recommended)
-		if config.ValidateBeforeExecution {
-			validationResult := s.ValidateRule(rule)
-			if !validationResult.Valid {
-				s.logger.Warn("Rule %s failed validation: %v", rule.Identifier(), validationResult.Issues)
-				// Create error result with validation details
-				var errorMsgs []string
-				for _, issue := range validationResult.Issues {
-					msg := fmt.Sprintf("%s: %s", issue.Type, issue.Message)
-					if issue.Details != "" {
-						msg += " - " + issue.Details
-					}
-					errorMsgs = append(errorMsgs, msg)
+// ScanWithSummary executes compliance checks like Scan and also returns a
+// summary of the scan with status counts and timings
+func (s *Scanner) ScanWithSummary(ctx context.Context, config ScanConfig) ([]CheckResult, ScanSummary, error) {
+	collector := &resultCollector{results: make([]CheckResult, len(config.Rules))}
+	run, err := s.scanWithSink(ctx, config, collector)
+	for i := range collector.results {
+		collector.results[i].Metadata.Environment = run.environment
+	}
+	return collector.results, run.summary, err
+}
+
+// ScanWithSink executes compliance checks like Scan, handing every result and
+// progress event to sink as soon as it is available instead of collecting them.
+// It returns once all rules have been reported. Streamed results carry the
+// scan environment without its end time, which LastScanEnvironment provides.
+// The summary of the scan is available from LastScanSummary.
+func (s *Scanner) ScanWithSink(ctx context.Context, config ScanConfig, sink ResultSink) error {
+	_, err := s.scanWithSink(ctx, config, sink)
+	return err
+}
+
+// scanWithSink runs a scan and returns it once complete
+func (s *Scanner) scanWithSink(ctx context.Context, config ScanConfig, sink ResultSink) (*scanRun, error) {
+	parent := ctx
+	if config.ScanTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, config.ScanTimeout, fmt.Errorf("scan timed out after %s", config.ScanTimeout))
+		defer cancel()
+	}
+
+	// Variables read from Kubernetes objects are resolved once per scan
+	variables, variableWarnings := s.resolveVariables(ctx, config.Variables)
+	config.Variables = variables
+
+	run := &scanRun{
+		config:           config,
+		sink:             &serializedSink{sink: sink, total: len(config.Rules)},
+		environment:      s.newScanEnvironment(ctx, config),
+		rules:            make([]RuleStatistics, len(config.Rules)),
+		variableWarnings: variableWarnings,
+	}
+	run.snapshot = newInputSnapshot(func(ctx context.Context, rule Rule, input Input) (interface{}, []string, error) {
+		return s.fetchInput(ctx, rule, input, config)
+	})
+
+	workers := config.MaxConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(config.Rules) {
+		workers = len(config.Rules)
+	}
+
+	// Rules are handed out by index so that results can be matched to their
+	// position in config.Rules
+	ruleIndexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range ruleIndexes {
+				rule := config.Rules[i]
+				// Every RULE_FINISHED event is preceded by RULE_STARTED,
+				// including for rules interrupted before any work
+				run.sink.emit(ScanEventRuleStarted, i, rule)
+				if ctx.Err() != nil {
+					errorMsg := fmt.Sprintf("Rule not started: %s", interruptionReason(ctx))
+					run.finish(i, rule, s.createErrorResultWithContext(rule, nil, errorMsg, nil, config.Variables))
+					continue
 				}
-				result := CheckResult{
-					ID:           rule.Identifier(),
-					Status:       CheckResultError,
-					Warnings:     append(validationResult.Warnings, errorMsgs...),
-					ErrorMessage: fmt.Sprintf("Rule validation failed: %s", strings.Join(errorMsgs, "; ")),
+				if result, ok := unevaluatedResult(config, rule); ok {
+					run.finish(i, rule, result)
+					continue
 				}
-				results = append(results, result)
-				continue
+				timer := startRuleTimer()
+				result := s.processRule(ctx, run, i, rule, timer)
+				run.rules[i].Timings = timer.stop()
+				run.finish(i, rule, finalizeStatus(config, rule, result))
 			}
-		}
+		}()
+	}
 
-		// Check rule type and handle accordingly
-		switch rule.Type() {
-		case RuleTypeCEL:
-			// Cast to CelRule for CEL-specific processing
-			celRule, ok := rule.(CelRule)
-			if !ok {
-				s.logger.Error("Failed to cast rule %s to CelRule", rule.Identifier())
-				result := s.createErrorResultWithContext(rule, nil, "Internal error: failed to cast rule to CelRule", nil, config.Variables)
-				results = append(results, result)
-				continue
-			}
+	for i := range config.Rules {
+		ruleIndexes <- i
+	}
+	close(ruleIndexes)
+	wg.Wait()
+
+	stats := run.snapshot.Stats()
+	s.logger.Info("Input snapshot: %d fetched, %d reused", stats.Misses, stats.Hits)
+	if s.stateStore != nil {
+		s.logger.Info("State store: %d of %d results reused", run.reused.Load(), len(config.Rules))
+	}
+	run.environment.EndTime = time.Now().UTC()
+	run.summary = run.summarize()
+	s.mu.Lock()
+	s.lastSnapshotStats = stats
+	s.lastEnvironment = run.environment
+	s.lastSummary = run.summary
+	s.mu.Unlock()
+
+	return run, parent.Err()
+}
 
-			// Process CEL rule
-			result := s.processCelRule(ctx, celRule, config)
-			results = append(results, result)
+// LastInputSnapshotStats returns the input fetch statistics of the most recent scan
+func (s *Scanner) LastInputSnapshotStats() InputSnapshotStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastSnapshotStats
+}
 
-		case RuleTypeRego, RuleTypeJSONPath, RuleTypeCustom:
-			// Future implementation for other rule types
-			s.logger.Warn("Rule type %s is not yet implemented, skipping rule: %s", rule.Type(), rule.Identifier())
-			results = append(results, CheckResult{
-				ID:           rule.Identifier(),
-				Status:       CheckResultNotApplicable,
-				Warnings:     []string{fmt.Sprintf("Rule type %s is not yet implemented", rule.Type())},
-				ErrorMessage: "",
-			})
-
-		default:
-			s.logger.Error("Unknown rule type: %s for rule: %s", rule.Type(), rule.Identifier())
-			results = append(results, CheckResult{
+// LastScanEnvironment returns the environment of the most recent scan,
+// including its end time
+func (s *Scanner) LastScanEnvironment() ScanEnvironment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastEnvironment
+}
+
+// LastScanSummary returns the summary of the most recent scan
+func (s *Scanner) LastScanSummary() ScanSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastSummary
+}
+
+// processRule validates and evaluates the rule at index according to its type
+func (s *Scanner) processRule(ctx context.Context, run *scanRun, index int, rule Rule, timer *ruleTimer) CheckResult {
+	config := run.config
+	s.logger.Debug("Processing rule: %s (type: %s)", rule.Identifier(), rule.Type())
+
+	if config.RuleTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, config.RuleTimeout, fmt.Errorf("rule timed out after %s", config.RuleTimeout))
+		defer cancel()
+	}
+
+	// Validate rule before processing (optional but recommended)
+	if config.ValidateBeforeExecution {
+		validationResult := s.validateRuleForScan(rule, config)
+		if !validationResult.Valid {
+			s.logger.Warn("Rule %s failed validation: %v", rule.Identifier(), validationResult.Issues)
+			// Create error result with validation details
+			var errorMsgs []string
+			for _, issue := range validationResult.Issues {
+				msg := fmt.Sprintf("%s: %s", issue.Type, issue.Message)
+				if issue.Details != "" {
+					msg += " - " + issue.Details
+				}
+				errorMsgs = append(errorMsgs, msg)
+			}
+			return CheckResult{
 				ID:           rule.Identifier(),
 				Status:       CheckResultError,
-				Warnings:     []string{fmt.Sprintf("Unknown rule type: %s", rule.Type())},
-				ErrorMessage: fmt.Sprintf("Unknown rule type: %s", rule.Type()),
-			})
+				Warnings:     append(validationResult.Warnings, errorMsgs...),
+				ErrorMessage: fmt.Sprintf("Rule validation failed: %s", strings.Join(errorMsgs, "; ")),
+			}
 		}
 	}
 
-	return results, nil
+	// Check rule type and handle accordingly
+	switch rule.Type() {
+	case RuleTypeCEL:
+		// Cast to CelRule for CEL-specific processing
+		celRule, ok := rule.(CelRule)
+		if !ok {
+			s.logger.Error("Failed to cast rule %s to CelRule", rule.Identifier())
+			return s.createErrorResultWithContext(rule, nil, "Internal error: failed to cast rule to CelRule", nil, config.Variables)
+		}
+
+		// Process CEL rule
+		return s.processCelRule(ctx, run, index, celRule, timer)
+
+	case RuleTypeRego, RuleTypeJSONPath, RuleTypeCustom:
+		// Future implementation for other rule types
+		s.logger.Warn("Rule type %s is not yet implemented, skipping rule: %s", rule.Type(), rule.Identifier())
+		return CheckResult{
+			ID:           rule.Identifier(),
+			Status:       CheckResultNotApplicable,
+			Warnings:     []string{fmt.Sprintf("Rule type %s is not yet implemented", rule.Type())},
+			ErrorMessage: "",
+		}
+
+	default:
+		s.logger.Error("Unknown rule type: %s for rule: %s", rule.Type(), rule.Identifier())
+		return CheckResult{
+			ID:           rule.Identifier(),
+			Status:       CheckResultError,
+			Warnings:     []string{fmt.Sprintf("Unknown rule type: %s", rule.Type())},
+			ErrorMessage: fmt.Sprintf("Unknown rule type: %s", rule.Type()),
+		}
+	}
 }
 
 // processCelRule processes a CEL rule and returns the result
-func (s *Scanner) processCelRule(ctx context.Context, rule CelRule, config ScanConfig) CheckResult {
+func (s *Scanner) processCelRule(ctx context.Context, run *scanRun, index int, rule CelRule, timer *ruleTimer) (result CheckResult) {
+	config := run.config
+
 	// Fetch resources for this rule
-	var resourceMap map[string]interface{}
-	var warnings []string
-	var err error
+	stopFetch := timer.measureFetch()
+	resourceMap, warnings, diagnostics, err := s.fetchRuleInputs(ctx, run, rule)
+	stopFetch()
+	warnings = append(run.variableWarningsFor(rule), warnings...)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Rule not completed: %s while fetching inputs", interruptionReason(ctx))
+		s.logger.Error("Rule %s: %s", rule.Identifier(), errorMsg)
+		return s.createErrorResultWithContext(rule, warnings, errorMsg, nil, config.Variables)
+	}
+	run.sink.emit(ScanEventInputsFetched, index, rule)
 
-	if config.ApiResourcePath != "" {
-		s.logger.Info("Using pre-fetched resources from: %s", config.ApiResourcePath)
-		resourceMap = s.collectResourcesFromFiles(config.ApiResourcePath, rule)
-	} else {
-		s.logger.Info("Fetching resources from API server")
-		resourceMap, warnings, err = s.resourceFetcher.FetchResources(ctx, rule, config.Variables)
+	if config.Evidence != nil {
+		evidence := s.collectEvidence(run, rule, resourceMap)
+		defer func() { result.Evidence = evidence }()
+	}
+
+	// Inputs that could not be fetched are handled by the fetch failure policy
+	if len(diagnostics) > 0 {
+		if result, done := s.applyFetchFailurePolicy(rule, diagnostics, warnings, config.FetchFailurePolicy); done {
+			return result
+		}
+		defer func() { result.FetchErrors = diagnostics }()
+	} else if s.stateStore != nil {
+		// Rules whose inputs are unchanged since the last scan reuse its result
+		fingerprint, err := s.ruleFingerprint(run, rule, resourceMap, warnings)
 		if err != nil {
-			s.logger.Error("Error fetching resources: %v", err)
-			// Continue with empty resource map to allow rule evaluation
-			resourceMap = make(map[string]interface{})
+			s.logger.Warn("Rule %s: not using the state store: %v", rule.Identifier(), err)
+		} else {
+			if previous, ok := s.lookupState(rule, fingerprint); ok {
+				s.logger.Debug("Rule %s: inputs unchanged, reusing previous result", rule.Identifier())
+				run.reused.Add(1)
+				return previous
+			}
+			defer func() { s.recordState(rule, fingerprint, result) }()
 		}
 	}
 
 	// Create CEL declarations with variables
-	declsList := s.createCelDeclarations(resourceMap, config.Variables)
+	declsList, err := s.createCelDeclarations(resourceMap, config.Variables)
+	if err != nil {
+		s.logger.Error("Rule %s: %v", rule.Identifier(), err)
+		return s.createErrorResultWithContext(rule, warnings, err.Error(), resourceMap, config.Variables)
+	}
+
+	// Per-object rules see the current object next to their inputs
+	forEachInput := ""
+	if perObject, ok := rule.(PerObjectRule); ok {
+		forEachInput = perObject.ForEachInput()
+	}
+	if forEachInput != "" {
+		declsList = append(declsList, decls.NewVar(ObjectVariableName, decls.Dyn))
+	}
+
+	// Expressions of a rule share its evaluation deadline
+	evalCtx := ctx
+	if config.EvalTimeout > 0 {
+		var cancel context.CancelFunc
+		evalCtx, cancel = context.WithTimeoutCause(ctx, config.EvalTimeout, fmt.Errorf("evaluation timed out after %s", config.EvalTimeout))
+		defer cancel()
+	}
+	activation, err := s.createActivation(resourceMap, config.Variables)
+	if err != nil {
+		s.logger.Error("Rule %s: %v", rule.Identifier(), err)
+		return s.createErrorResultWithContext(rule, warnings, err.Error(), resourceMap, config.Variables)
+	}
+
+	// Rules whose applicability condition does not hold are not evaluated
+	if applicable, ok := rule.(ApplicableRule); ok && applicable.ApplicabilityExpression() != "" {
+		if result, done := s.checkApplicability(evalCtx, rule, applicable, declsList, activation, warnings, config, timer); done {
+			return result
+		}
+	}
+
+	compiled, buildErr := s.buildProgram(rule.Expression(), declsList, config, timer)
+	if buildErr != nil {
+		errorMsg := buildErr.Error()
+		if buildErr.compileErr != nil {
+			// Try to get more detailed error information using validation API
+			errorMsg = s.getDetailedCompilationError(rule, buildErr.compileErr)
+		}
+		s.logger.Error("Failed to build CEL program for rule %s: %s", rule.Identifier(), errorMsg)
+		return s.createErrorResultWithContext(rule, warnings, errorMsg, resourceMap, config.Variables)
+	}
+
+	message := s.buildMessageProgram(rule, declsList, &warnings, config, timer)
+
+	if forEachInput != "" {
+		return s.evaluatePerObject(evalCtx, compiled, message, activation, rule, forEachInput, warnings, config)
+	}
+
+	// Evaluate the CEL expression
+	return s.evaluateCelExpression(evalCtx, compiled, message, activation, rule, warnings, config)
+}
+
+// programBuildError describes why a CEL program could not be built.
+// compileErr is set when the expression itself failed to compile.
+type programBuildError struct {
+	message    string
+	compileErr error
+}
+
+func (e *programBuildError) Error() string { return e.message }
+
+// buildProgram returns the program of an expression checked against declsList,
+// reusing a program compiled for the same expression, declarations and
+// environment. Compilation time is added to the rule timer.
+func (s *Scanner) buildProgram(expression string, declsList []*expr.Decl, config ScanConfig, timer *ruleTimer) (*compiledProgram, *programBuildError) {
+	programOpts, optionsKey := programOptions(config)
+	cacheKey := programCacheKey(s.envKey, optionsKey, expression, declsList)
+	if compiled, cached := s.lookupProgram(cacheKey); cached {
+		return compiled, nil
+	}
+	defer timer.measureCompile()()
 
 	// Create CEL environment
 	env, err := s.createCelEnvironment(declsList)
 	if err != nil {
-		// Create an error result for this rule and continue with next rule
-		result := s.createErrorResultWithContext(rule, warnings, fmt.Sprintf("Failed to create CEL environment: %v", err), resourceMap, config.Variables)
-		s.logger.Error("Failed to create CEL environment for rule %s: %v", rule.Identifier(), err)
-		return result
+		return nil, &programBuildError{message: fmt.Sprintf("Failed to create CEL environment: %v", err)}
 	}
 
 	// Compile the CEL expression - handle compilation errors gracefully
-	ast, err := s.compileCelExpression(env, rule.Expression())
+	ast, err := s.compileCelExpression(env, expression)
 	if err != nil {
-		// Try to get more detailed error information using validation API
-		detailedError := s.getDetailedCompilationError(rule, err)
-		result := s.createErrorResultWithContext(rule, warnings, detailedError, resourceMap, config.Variables)
-		s.logger.Error("Failed to compile CEL expression for rule %s: %v", rule.Identifier(), detailedError)
-		return result
+		return nil, &programBuildError{message: err.Error(), compileErr: err}
 	}
 
-	// Evaluate the CEL expression
-	result := s.evaluateCelExpression(env, ast, resourceMap, rule, warnings, config.Variables)
-	return result
+	// Create the CEL program
+	prg, err := env.Program(ast, programOpts...)
+	if err != nil {
+		return nil, &programBuildError{message: fmt.Sprintf("Failed to create CEL program: %v", err)}
+	}
+
+	compiled := &compiledProgram{key: cacheKey, env: env, ast: ast, program: prg, options: programOpts}
+	s.storeProgram(compiled)
+	return compiled, nil
+}
+
+// checkApplicability evaluates the applicability condition of a rule. It
+// returns true with a NOT-APPLICABLE or ERROR result when the rule must not be
+// evaluated any further.
+func (s *Scanner) checkApplicability(ctx context.Context, rule Rule, applicable ApplicableRule, declsList []*expr.Decl, activation map[string]interface{}, warnings []string, config ScanConfig, timer *ruleTimer) (CheckResult, bool) {
+	expression := applicable.ApplicabilityExpression()
+
+	compiled, buildErr := s.buildProgram(expression, declsList, config, timer)
+	if buildErr != nil {
+		errorMsg := fmt.Sprintf("Invalid applicability expression: %v", buildErr)
+		s.logger.Error("Rule %s: %s", rule.Identifier(), errorMsg)
+		return s.createErrorResultWithContext(rule, warnings, errorMsg, nil, config.Variables), true
+	}
+
+	out, details, err := compiled.program.ContextEval(ctx, activation)
+	if err != nil {
+		errorMsg, limited := evaluationLimitError(ctx, err, details, config)
+		if !limited {
+			errorMsg = fmt.Sprintf("Failed to evaluate applicability expression: %v", err)
+		}
+		s.logger.Error("Rule %s: %s", rule.Identifier(), errorMsg)
+		return s.createErrorResultWithContext(rule, warnings, errorMsg, nil, config.Variables), true
+	}
+
+	isApplicable, ok := out.Value().(bool)
+	if !ok {
+		errorMsg := fmt.Sprintf("Applicability expression must evaluate to a bool, got %s", out.Type().TypeName())
+		s.logger.Error("Rule %s: %s", rule.Identifier(), errorMsg)
+		return s.createErrorResultWithContext(rule, warnings, errorMsg, nil, config.Variables), true
+	}
+	if isApplicable {
+		return CheckResult{}, false
+	}
+
+	reason := applicable.NotApplicableReason()
+	if reason == "" {
+		reason = fmt.Sprintf("applicability condition not met: %s", expression)
+	}
+	s.logger.Info("Rule %s is not applicable: %s", rule.Identifier(), reason)
+	return CheckResult{
+		ID:       rule.Identifier(),
+		Status:   CheckResultNotApplicable,
+		Metadata: CheckResultMetadata{},
+		Warnings: warnings,
+		Reason:   reason,
+	}, true
+}
+
+// interruptCheckFrequency is the number of comprehension iterations between
+// checks for an expired evaluation context. The counter is shared by nested
+// comprehensions, so anything but 1 lets an outer loop miss its checks.
+const interruptCheckFrequency = 1
+
+// programOptions returns the CEL program options for a scan together with a
+// key identifying them in the program cache
+func programOptions(config ScanConfig) ([]cel.ProgramOption, string) {
+	opts := []cel.ProgramOption{
+		cel.EvalOptions(cel.OptTrackCost),
+		cel.InterruptCheckFrequency(interruptCheckFrequency),
+	}
+	if config.CostLimit > 0 {
+		opts = append(opts, cel.CostLimit(config.CostLimit))
+	}
+	return opts, fmt.Sprintf("cost=%d", config.CostLimit)
+}
+
+// lookupProgram returns a cached compiled program if caching is enabled
+func (s *Scanner) lookupProgram(key string) (*compiledProgram, bool) {
+	if s.programCache == nil {
+		return nil, false
+	}
+	return s.programCache.get(key)
+}
+
+// storeProgram adds a compiled program to the cache if caching is enabled
+func (s *Scanner) storeProgram(program *compiledProgram) {
+	if s.programCache != nil {
+		s.programCache.add(program)
+	}
+}
+
+// fetchRuleInputs collects the data for every input of a rule from the scan snapshot.
+// An error is returned only when ctx is done before all inputs were fetched.
+func (s *Scanner) fetchRuleInputs(ctx context.Context, run *scanRun, rule Rule) (map[string]interface{}, []string, []FetchDiagnostic, error) {
+	resourceMap := make(map[string]interface{})
+	var warnings []string
+	var diagnostics []FetchDiagnostic
+
+	if run.config.ApiResourcePath != "" {
+		s.logger.Info("Using pre-fetched resources from: %s", run.config.ApiResourcePath)
+	} else {
+		s.logger.Info("Fetching resources from API server")
+	}
+
+	for _, input := range rule.Inputs() {
+		// Only Kubernetes inputs are available from pre-fetched files
+		if run.config.ApiResourcePath != "" && input.Type() != InputTypeKubernetes {
+			continue
+		}
+
+		data, inputWarnings, err := run.snapshot.Get(ctx, rule, input)
+		warnings = append(warnings, inputWarnings...)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, warnings, nil, ctxErr
+			}
+			if IsOptionalInput(input) && IsMissingInputError(err) {
+				warnings = append(warnings, AbsentInputWarning(input, err))
+				resourceMap[input.Name()] = AbsentInputValue(input)
+				continue
+			}
+			s.logger.Error("Error fetching input %s: %v", input.Name(), err)
+			diagnostics = append(diagnostics, newFetchDiagnostic(input, err))
+			continue
+		}
+		resourceMap[input.Name()] = data
+	}
+
+	return resourceMap, warnings, diagnostics, nil
+}
+
+// interruptionReason describes why ctx ended, preferring the timeout cause
+// recorded by Scan over the bare context error
+func interruptionReason(ctx context.Context) string {
+	cause := context.Cause(ctx)
+	switch {
+	case cause == nil:
+		return "context is still active"
+	case errors.Is(cause, context.Canceled):
+		return "scan was cancelled"
+	case errors.Is(cause, context.DeadlineExceeded):
+		return "scan deadline exceeded"
+	default:
+		return cause.Error()
+	}
+}
+
+// fetchInput retrieves a single input from pre-fetched files or the resource fetcher
+func (s *Scanner) fetchInput(ctx context.Context, rule Rule, input Input, config ScanConfig) (interface{}, []string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if config.ApiResourcePath != "" {
+		data, err := s.collectResourceFromFile(config.ApiResourcePath, input)
+		return data, nil, err
+	}
+
+	if s.resourceFetcher == nil {
+		return nil, nil, fmt.Errorf("no resource fetcher configured for input %s", input.Name())
+	}
+
+	resourceMap, warnings, err := s.resourceFetcher.FetchResources(ctx, &singleInputRule{Rule: rule, input: input}, config.Variables)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	data, ok := resourceMap[input.Name()]
+	if !ok {
+		return nil, warnings, fmt.Errorf("no data returned for input %s", input.Name())
+	}
+	return data, warnings, nil
 }
 
 // getDetailedCompilationError uses the validation API to get detailed error information
@@ -311,65 +826,50 @@ func (s *Scanner) createErrorResultWithContext(rule Rule, warnings []string, err
 	return result
 }
 
-// collectResourcesFromFiles collects resources from pre-fetched files
-func (s *Scanner) collectResourcesFromFiles(resourceDir string, rule Rule) map[string]interface{} {
-	resultMap := make(map[string]interface{})
-
-	for _, input := range rule.Inputs() {
-		// Only handle Kubernetes inputs for file collection
-		if input.Type() != InputTypeKubernetes {
-			continue
-		}
-
-		kubeSpec, ok := input.Spec().(KubernetesInputSpec)
-		if !ok {
-			s.logger.Error("Invalid Kubernetes input spec for input: %s", input.Name())
-			continue
-		}
+// collectResourceFromFile reads a single Kubernetes input from pre-fetched files
+func (s *Scanner) collectResourceFromFile(resourceDir string, input Input) (interface{}, error) {
+	kubeSpec, ok := input.Spec().(KubernetesInputSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid Kubernetes input spec for input: %s", input.Name())
+	}
 
-		// Define the GroupVersionResource for the current input
-		gvr := schema.GroupVersionResource{
-			Group:    kubeSpec.ApiGroup(),
-			Version:  kubeSpec.Version(),
-			Resource: kubeSpec.ResourceType(),
-		}
+	// Define the GroupVersionResource for the current input
+	gvr := schema.GroupVersionResource{
+		Group:    kubeSpec.ApiGroup(),
+		Version:  kubeSpec.Version(),
+		Resource: kubeSpec.ResourceType(),
+	}
 
-		// Derive the resource path
-		objPath := DeriveResourcePath(gvr, kubeSpec.Namespace()) + ".json"
-		filePath := filepath.Join(resourceDir, objPath)
+	// Derive the resource path
+	objPath := DeriveResourcePath(gvr, kubeSpec.Namespace()) + ".json"
+	filePath := filepath.Join(resourceDir, objPath)
 
-		// Read the file content
-		fileContent, err := os.ReadFile(filePath)
-		if err != nil {
-			s.logger.Error("Failed to read file %s: %v", filePath, err)
-			continue
-		}
+	// Read the file content
+	fileContent, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
 
-		// Parse based on resource type
-		if strings.Contains(kubeSpec.ResourceType(), "/") {
-			// Subresource
-			result := &unstructured.Unstructured{}
-			if err := json.Unmarshal(fileContent, result); err != nil {
-				s.logger.Error("Failed to parse JSON from file %s: %v", filePath, err)
-				continue
-			}
-			resultMap[input.Name()] = result
-		} else {
-			// Regular resource list
-			results := &unstructured.UnstructuredList{}
-			if err := json.Unmarshal(fileContent, results); err != nil {
-				s.logger.Error("Failed to parse JSON from file %s: %v", filePath, err)
-				continue
-			}
-			resultMap[input.Name()] = results
+	// Parse based on resource type
+	if strings.Contains(kubeSpec.ResourceType(), "/") {
+		// Subresource
+		result := &unstructured.Unstructured{}
+		if err := json.Unmarshal(fileContent, result); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON from file %s: %w", filePath, err)
 		}
+		return result, nil
 	}
 
-	return resultMap
+	// Regular resource list
+	results := &unstructured.UnstructuredList{}
+	if err := json.Unmarshal(fileContent, results); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON from file %s: %w", filePath, err)
+	}
+	return results, nil
 }
 
 // createCelDeclarations creates CEL declarations for the given resource map and variables
-func (s *Scanner) createCelDeclarations(resourceMap map[string]interface{}, variables []CelVariable) []*expr.Decl {
+func (s *Scanner) createCelDeclarations(resourceMap map[string]interface{}, variables []CelVariable) ([]*expr.Decl, error) {
 	declsList := []*expr.Decl{}
 
 	// Add resource declarations
@@ -377,12 +877,16 @@ func (s *Scanner) createCelDeclarations(resourceMap map[string]interface{}, vari
 		declsList = append(declsList, decls.NewVar(k, decls.Dyn))
 	}
 
-	// Add variable declarations
+	// Add variable declarations with their declared types
 	for _, variable := range variables {
-		declsList = append(declsList, decls.NewVar(variable.Name(), decls.String))
+		decl, err := variableDecl(variable)
+		if err != nil {
+			return nil, err
+		}
+		declsList = append(declsList, decl)
 	}
 
-	return declsList
+	return declsList, nil
 }
 
 // createCelEnvironment creates a CEL environment with custom functions
@@ -399,9 +903,11 @@ func (s *Scanner) createCelEnvironment(declsList []*expr.Decl) (*cel.Env, error)
 
 	envOpts := []cel.EnvOption{
 		cel.StdLib(),
+		cel.OptionalTypes(),
 		jsonenvOpts,
 		yamlenvOpts,
 	}
+	envOpts = append(envOpts, s.envOptions...)
 
 	// Add variable declarations if provided
 	if len(declsList) > 0 {
@@ -467,8 +973,10 @@ func (s *Scanner) compileCelExpression(env *cel.Env, expression string) (*cel.As
 	return ast, nil
 }
 
-// evaluateCelExpression evaluates a CEL expression and returns the result
-func (s *Scanner) evaluateCelExpression(env *cel.Env, ast *cel.Ast, resourceMap map[string]interface{}, rule Rule, warnings []string, variables []CelVariable) CheckResult {
+// evaluateCelExpression evaluates a CEL expression and returns the result.
+// Evaluation stops with an ERROR result once ctx is done or the cost limit of
+// the scan is exceeded. Failures are described by the message program, if any.
+func (s *Scanner) evaluateCelExpression(ctx context.Context, compiled, message *compiledProgram, activation map[string]interface{}, rule Rule, warnings []string, config ScanConfig) CheckResult {
 	result := CheckResult{
 		ID:           rule.Identifier(),
 		Status:       CheckResultError,
@@ -477,35 +985,22 @@ func (s *Scanner) evaluateCelExpression(env *cel.Env, ast *cel.Ast, resourceMap
 		ErrorMessage: "",
 	}
 
-	// Prepare evaluation variables
-	evalVars := map[string]interface{}{}
-	for k, v := range resourceMap {
-		s.logger.Debug("Evaluating variable %s: %v", k, v)
-		evalVars[k] = toCelValue(v)
-	}
-
-	// Add variables to evaluation context
-	for _, variable := range variables {
-		evalVars[variable.Name()] = variable.Value()
-	}
-
-	// Create and run the CEL program
-	prg, err := env.Program(ast)
+	// Run the CEL program
+	out, details, err := compiled.program.ContextEval(ctx, activation)
 	if err != nil {
-		result.Status = CheckResultError
-		result.Warnings = append(result.Warnings, fmt.Sprintf("Failed to create CEL program: %v", err))
-		return result
-	}
-
-	out, _, err := prg.Eval(evalVars)
-	if err != nil {
-		if strings.HasPrefix(err.Error(), "no such key") {
-			s.logger.Warn("Warning: %s in rule %s", err, rule.Identifier())
-			result.Warnings = append(result.Warnings, fmt.Sprintf("Warning: %s", err))
-			result.Status = CheckResultFail
+		if errorMsg, limited := evaluationLimitError(ctx, err, details, config); limited {
+			s.logger.Error("Rule %s: %s", rule.Identifier(), errorMsg)
+			result.Warnings = append(result.Warnings, errorMsg)
+			result.ErrorMessage = errorMsg
 			return result
 		}
 
+		// Reads of absent fields are reported according to the rule's policy
+		if path, missing := findMissingPath(compiled.ast, activation, compiled.evaluationState(ctx, activation)); missing {
+			s.logger.Warn("Rule %s: %s (%v)", rule.Identifier(), missingDataMessage(path), err)
+			return missingDataResult(result, rule, path)
+		}
+
 		result.Status = CheckResultError
 		result.Warnings = append(result.Warnings, fmt.Sprintf("Failed to evaluate CEL expression: %v", err))
 		return result
@@ -514,6 +1009,15 @@ func (s *Scanner) evaluateCelExpression(env *cel.Env, ast *cel.Ast, resourceMap
 	// Determine result status based on evaluation outcome
 	if out.Value() == false {
 		result.Status = CheckResultFail
+		result.Explanation = s.explanationFor(ctx, compiled, rule, activation, config)
+		if message != nil {
+			text, err := renderMessage(ctx, message, activation)
+			if err != nil {
+				s.logger.Warn("Rule %s: %v", rule.Identifier(), err)
+				result.Warnings = append(result.Warnings, err.Error())
+			}
+			result.ErrorMessage = text
+		}
 	} else {
 		result.Status = CheckResultPass
 		s.logger.Info("%s: %v", rule.Identifier(), out)
@@ -522,6 +1026,45 @@ func (s *Scanner) evaluateCelExpression(env *cel.Env, ast *cel.Ast, resourceMap
 	return result
 }
 
+// createActivation binds fetched inputs and variables for evaluation
+func (s *Scanner) createActivation(resourceMap map[string]interface{}, variables []CelVariable) (map[string]interface{}, error) {
+	// Prepare evaluation variables
+	evalVars := map[string]interface{}{}
+	for k, v := range resourceMap {
+		s.logger.Debug("Evaluating variable %s: %v", k, v)
+		evalVars[k] = toCelValue(v)
+	}
+
+	// Add variables to evaluation context
+	for _, variable := range variables {
+		value, err := ParseVariableValue(variable)
+		if err != nil {
+			return nil, err
+		}
+		evalVars[variable.Name()] = value
+	}
+
+	return evalVars, nil
+}
+
+// evaluationLimitError describes an evaluation stopped by a timeout,
+// cancellation or the cost limit, including the cost used until then
+func evaluationLimitError(ctx context.Context, err error, details *cel.EvalDetails, config ScanConfig) (string, bool) {
+	var cost uint64
+	if details != nil && details.ActualCost() != nil {
+		cost = *details.ActualCost()
+	}
+
+	var cancelled interpreter.EvalCancelledError
+	if errors.As(err, &cancelled) && cancelled.Cause == interpreter.CostLimitExceeded {
+		return fmt.Sprintf("CEL evaluation exceeded the cost limit of %d (cost used: %d)", config.CostLimit, cost), true
+	}
+	if ctx.Err() != nil {
+		return fmt.Sprintf("CEL evaluation interrupted: %s (cost used: %d)", interruptionReason(ctx), cost), true
+	}
+	return "", false
+}
+
 // DeriveResourcePath creates a resource path from GroupVersionResource and namespace
 func DeriveResourcePath(gvr schema.GroupVersionResource, namespace string) string {
 	if namespace != "" {
@@ -18,9 +18,13 @@ package scanner
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -193,6 +197,65 @@ func TestSaveResults(t *testing.T) {
 	}
 }
 
+func TestScanner_ConcurrentScan(t *testing.T) {
+	var rules []Rule
+	for i := 0; i < 20; i++ {
+		expression := "pods.items.size() == 1"
+		if i%2 == 1 {
+			expression = "pods.items.size() == 0"
+		}
+		rule, err := NewRuleBuilder(fmt.Sprintf("rule-%02d", i), RuleTypeCEL).
+			WithKubernetesInput("pods", "", "v1", "pods", fmt.Sprintf("namespace-%02d", i), "").
+			SetCelExpression(expression).
+			BuildCelRule()
+		if err != nil {
+			t.Fatalf("Failed to build rule: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	fetcher := &countingFetcher{
+		delay: 10 * time.Millisecond,
+		data: map[string]interface{}{
+			"pods": map[string]interface{}{
+				"items": []interface{}{map[string]interface{}{"metadata": map[string]interface{}{"name": "a"}}},
+			},
+		},
+	}
+	scanner := NewScanner(fetcher, &TestLogger{t: t})
+
+	results, err := scanner.Scan(context.Background(), ScanConfig{
+		Rules:          rules,
+		MaxConcurrency: 4,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != len(rules) {
+		t.Fatalf("Expected %d results, got %d", len(rules), len(results))
+	}
+
+	for i, result := range results {
+		if result.ID != rules[i].Identifier() {
+			t.Errorf("Result %d: expected ID %s, got %s", i, rules[i].Identifier(), result.ID)
+		}
+		expected := CheckResultPass
+		if i%2 == 1 {
+			expected = CheckResultFail
+		}
+		if result.Status != expected {
+			t.Errorf("Result %d: expected status %s, got %s", i, expected, result.Status)
+		}
+	}
+
+	if peak := fetcher.peak.Load(); peak > 4 {
+		t.Errorf("Expected at most 4 concurrent fetches, got %d", peak)
+	} else if peak < 2 {
+		t.Errorf("Expected rules to be fetched concurrently, peak was %d", peak)
+	}
+}
+
 // setupTestData creates test data directory with mock resources
 func setupTestData(t *testing.T) string {
 	testDataDir := t.TempDir()
@@ -233,6 +296,221 @@ func (v *TestCelVariable) Namespace() string                         { return v.
 func (v *TestCelVariable) Value() string                             { return v.value }
 func (v *TestCelVariable) GroupVersionKind() schema.GroupVersionKind { return v.gvk }
 
+// podList returns a pods list input holding count items
+func podList(count int) map[string]interface{} {
+	items := make([]interface{}, count)
+	for i := range items {
+		items[i] = map[string]interface{}{"metadata": map[string]interface{}{"name": fmt.Sprintf("pod-%d", i)}}
+	}
+	return map[string]interface{}{"items": items}
+}
+
+func TestScanner_EvaluationLimits(t *testing.T) {
+	rule, err := NewRuleBuilder("nested-pods", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		SetCelExpression("pods.items.all(a, pods.items.all(b, a.metadata.name != '' && b.metadata.name != ''))").
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		pods          int
+		config        ScanConfig
+		expectedError string
+	}{
+		{
+			name:          "cost limit exceeded",
+			pods:          100,
+			config:        ScanConfig{CostLimit: 1000},
+			expectedError: "CEL evaluation exceeded the cost limit of 1000 (cost used: ",
+		},
+		{
+			name:          "evaluation timeout",
+			pods:          5000,
+			config:        ScanConfig{EvalTimeout: 20 * time.Millisecond},
+			expectedError: "CEL evaluation interrupted: evaluation timed out after 20ms (cost used: ",
+		},
+		{
+			name:   "within limits",
+			pods:   10,
+			config: ScanConfig{CostLimit: 100000, EvalTimeout: time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(tt.pods)}}
+			scanner := NewScanner(fetcher, &MockLogger{})
+
+			tt.config.Rules = []Rule{rule}
+			start := time.Now()
+			results, err := scanner.Scan(context.Background(), tt.config)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("Expected the limit to stop evaluation early, took %s", elapsed)
+			}
+
+			result := results[0]
+			if tt.expectedError == "" {
+				if result.Status != CheckResultPass {
+					t.Errorf("Expected PASS, got %s (%s)", result.Status, result.ErrorMessage)
+				}
+				return
+			}
+			if result.Status != CheckResultError {
+				t.Errorf("Expected ERROR, got %s", result.Status)
+			}
+			if !strings.HasPrefix(result.ErrorMessage, tt.expectedError) {
+				t.Errorf("Expected error message starting with %q, got %q", tt.expectedError, result.ErrorMessage)
+			}
+		})
+	}
+}
+
+func TestScanner_ValidateCostBeforeExecution(t *testing.T) {
+	rule, err := NewRuleBuilder("nested-pods", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		SetCelExpression("pods.items.all(a, pods.items.all(b, a.metadata.name != b.metadata.name))").
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+
+	fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(1)}}
+	scanner := NewScanner(fetcher, &MockLogger{})
+	config := ScanConfig{Rules: []Rule{rule}, CostLimit: 100000, ValidateBeforeExecution: true}
+
+	results, err := scanner.Scan(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[0].Status != CheckResultError {
+		t.Fatalf("Expected ERROR, got %s", results[0].Status)
+	}
+	if !strings.Contains(results[0].ErrorMessage, "exceeds the cost limit of 100000") {
+		t.Errorf("Expected a cost limit validation error, got %q", results[0].ErrorMessage)
+	}
+	if calls := fetcher.calls.Load(); calls != 0 {
+		t.Errorf("Expected the rule to be rejected before fetching, got %d fetches", calls)
+	}
+
+	if result := scanner.ValidateAllRules(config)[rule.Identifier()]; result.Valid {
+		t.Error("Expected ValidateAllRules to reject the rule")
+	}
+	config.CostLimit = 0
+	if result := scanner.ValidateAllRules(config)[rule.Identifier()]; !result.Valid {
+		t.Errorf("Expected the rule to be valid without a cost limit, got %v", result.Issues)
+	}
+}
+
+func TestScanner_Applicability(t *testing.T) {
+	tests := []struct {
+		name           string
+		pods           int
+		applicability  string
+		reason         string
+		expectedStatus CheckResultStatus
+		expectedReason string
+	}{
+		{
+			name:           "applicable rule is evaluated",
+			pods:           2,
+			applicability:  "pods.items.size() > 0",
+			reason:         "no pods exist",
+			expectedStatus: CheckResultPass,
+		},
+		{
+			name:           "condition false",
+			pods:           0,
+			applicability:  "pods.items.size() > 0",
+			reason:         "no pods exist",
+			expectedStatus: CheckResultNotApplicable,
+			expectedReason: "no pods exist",
+		},
+		{
+			name:           "default reason",
+			pods:           0,
+			applicability:  "pods.items.size() > 0",
+			expectedStatus: CheckResultNotApplicable,
+			expectedReason: "applicability condition not met: pods.items.size() > 0",
+		},
+		{
+			name:           "non-boolean condition",
+			pods:           1,
+			applicability:  "pods.items.size()",
+			expectedStatus: CheckResultError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewRuleBuilder("pods-named", RuleTypeCEL).
+				WithKubernetesInput("pods", "", "v1", "pods", "", "").
+				SetCelExpression("pods.items.all(p, p.metadata.name != '')").
+				WithApplicability(tt.applicability, tt.reason).
+				BuildCelRule()
+			if err != nil {
+				t.Fatalf("Failed to build rule: %v", err)
+			}
+
+			fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(tt.pods)}}
+			scanner := NewScanner(fetcher, &TestLogger{t: t})
+			results, err := scanner.Scan(context.Background(), ScanConfig{Rules: []Rule{rule}})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if results[0].Status != tt.expectedStatus {
+				t.Errorf("Expected %s, got %s (%s)", tt.expectedStatus, results[0].Status, results[0].ErrorMessage)
+			}
+			if results[0].Reason != tt.expectedReason {
+				t.Errorf("Expected reason %q, got %q", tt.expectedReason, results[0].Reason)
+			}
+		})
+	}
+}
+
+// countingFetcher is a ResourceFetcher returning static data that records
+// how many fetches it served and how many ran at the same time
+type countingFetcher struct {
+	data  map[string]interface{}
+	delay time.Duration
+
+	calls    atomic.Int32
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (f *countingFetcher) FetchResources(ctx context.Context, rule Rule, variables []CelVariable) (map[string]interface{}, []string, error) {
+	f.calls.Add(1)
+	current := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		peak := f.peak.Load()
+		if current <= peak || f.peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+
+	result := make(map[string]interface{})
+	for _, input := range rule.Inputs() {
+		if value, ok := f.data[input.Name()]; ok {
+			result[input.Name()] = value
+		}
+	}
+	return result, nil, nil
+}
+
 type TestLogger struct {
 	t *testing.T
 }
@@ -0,0 +1,128 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"sync"
+	"time"
+)
+
+// ScanEventType identifies a progress event emitted during a scan
+type ScanEventType string
+
+const (
+	// ScanEventRuleStarted is emitted when a worker starts processing a rule
+	ScanEventRuleStarted ScanEventType = "RULE_STARTED"
+
+	// ScanEventInputsFetched is emitted once all inputs of a rule are available
+	ScanEventInputsFetched ScanEventType = "INPUTS_FETCHED"
+
+	// ScanEventRuleFinished is emitted when the result of a rule is ready
+	ScanEventRuleFinished ScanEventType = "RULE_FINISHED"
+)
+
+// ScanEvent reports the progress of a single rule within a scan
+type ScanEvent struct {
+	// Type is the kind of progress being reported
+	Type ScanEventType `json:"type"`
+
+	// RuleID is the identifier of the rule the event belongs to
+	RuleID string `json:"ruleId"`
+
+	// Index is the position of the rule in ScanConfig.Rules
+	Index int `json:"index"`
+
+	// Total is the number of rules in the scan
+	Total int `json:"total"`
+
+	// Time is when the event occurred
+	Time time.Time `json:"time"`
+
+	// Result is the rule result, set only for ScanEventRuleFinished
+	Result *CheckResult `json:"result,omitempty"`
+}
+
+// ResultSink receives scan progress and results as soon as they are
+// available. Calls are serialized, so implementations need no locking, but a
+// slow sink holds up the workers reporting to it.
+type ResultSink interface {
+	// OnEvent is called for every progress event
+	OnEvent(event ScanEvent)
+
+	// OnResult is called with the result of each rule, index being its
+	// position in ScanConfig.Rules. Results arrive in completion order.
+	OnResult(index int, result CheckResult)
+}
+
+// ResultSinkFunc adapts a function receiving results to a ResultSink that
+// ignores progress events
+type ResultSinkFunc func(index int, result CheckResult)
+
+// OnEvent ignores progress events
+func (f ResultSinkFunc) OnEvent(event ScanEvent) {}
+
+// OnResult calls f
+func (f ResultSinkFunc) OnResult(index int, result CheckResult) { f(index, result) }
+
+// resultCollector is the ResultSink used by Scan to gather results in rule order
+type resultCollector struct {
+	results []CheckResult
+}
+
+func (c *resultCollector) OnEvent(event ScanEvent) {}
+
+func (c *resultCollector) OnResult(index int, result CheckResult) {
+	c.results[index] = result
+}
+
+// serializedSink forwards events and results to a sink one call at a time
+type serializedSink struct {
+	mu    sync.Mutex
+	sink  ResultSink
+	total int
+}
+
+// emit reports a progress event for the rule at index
+func (s *serializedSink) emit(eventType ScanEventType, index int, rule Rule) {
+	event := ScanEvent{
+		Type:   eventType,
+		RuleID: rule.Identifier(),
+		Index:  index,
+		Total:  s.total,
+		Time:   time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sink.OnEvent(event)
+}
+
+// finish delivers the result of the rule at index followed by its finished event
+func (s *serializedSink) finish(index int, rule Rule, result CheckResult) {
+	event := ScanEvent{
+		Type:   ScanEventRuleFinished,
+		RuleID: rule.Identifier(),
+		Index:  index,
+		Total:  s.total,
+		Time:   time.Now(),
+		Result: &result,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sink.OnResult(index, result)
+	s.sink.OnEvent(event)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// recordingSink records everything it receives; the scanner serializes calls
+type recordingSink struct {
+	events  []ScanEvent
+	results map[int]CheckResult
+}
+
+func (r *recordingSink) OnEvent(event ScanEvent) {
+	r.events = append(r.events, event)
+}
+
+func (r *recordingSink) OnResult(index int, result CheckResult) {
+	if r.results == nil {
+		r.results = make(map[int]CheckResult)
+	}
+	r.results[index] = result
+}
+
+// eventTypesFor returns the event types received for the rule at index
+func (r *recordingSink) eventTypesFor(index int) []ScanEventType {
+	var types []ScanEventType
+	for _, event := range r.events {
+		if event.Index == index {
+			types = append(types, event.Type)
+		}
+	}
+	return types
+}
+
+func TestScanner_ScanWithSink(t *testing.T) {
+	var rules []Rule
+	for i := 0; i < 6; i++ {
+		rule, err := NewRuleBuilder(fmt.Sprintf("rule-%d", i), RuleTypeCEL).
+			WithKubernetesInput("pods", "", "v1", "pods", fmt.Sprintf("namespace-%d", i), "").
+			SetCelExpression(fmt.Sprintf("pods.items.size() == %d", i%2)).
+			BuildCelRule()
+		if err != nil {
+			t.Fatalf("Failed to build rule: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(1)}}
+	scanner := NewScanner(fetcher, &TestLogger{t: t})
+	config := ScanConfig{Rules: rules, MaxConcurrency: 3}
+
+	sink := &recordingSink{}
+	if err := scanner.ScanWithSink(context.Background(), config, sink); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedTypes := []ScanEventType{ScanEventRuleStarted, ScanEventInputsFetched, ScanEventRuleFinished}
+	for i, rule := range rules {
+		if types := sink.eventTypesFor(i); !reflect.DeepEqual(types, expectedTypes) {
+			t.Errorf("Rule %d: expected events %v, got %v", i, expectedTypes, types)
+		}
+
+		result, ok := sink.results[i]
+		if !ok {
+			t.Fatalf("Rule %d: no result delivered", i)
+		}
+		if result.ID != rule.Identifier() {
+			t.Errorf("Rule %d: expected result for %s, got %s", i, rule.Identifier(), result.ID)
+		}
+	}
+
+	for _, event := range sink.events {
+		if event.Total != len(rules) {
+			t.Errorf("Expected total %d, got %d", len(rules), event.Total)
+		}
+		if (event.Type == ScanEventRuleFinished) != (event.Result != nil) {
+			t.Errorf("Event %s: unexpected result %v", event.Type, event.Result)
+		}
+	}
+
+	// Scan collects the same results in rule order
+	results, err := scanner.Scan(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, result := range results {
+		if result.ID != sink.results[i].ID || result.Status != sink.results[i].Status {
+			t.Errorf("Rule %d: Scan returned %s/%s, sink received %s/%s",
+				i, result.ID, result.Status, sink.results[i].ID, sink.results[i].Status)
+		}
+	}
+}
+
+func TestScanner_ScanWithSinkCancelled(t *testing.T) {
+	fetcher := &countingFetcher{data: map[string]interface{}{}}
+	scanner := NewScanner(fetcher, &TestLogger{t: t})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sink := &recordingSink{}
+	err := scanner.ScanWithSink(ctx, ScanConfig{Rules: buildTimeoutRules(t, 3)}, sink)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(sink.results) != 3 {
+		t.Errorf("Expected every rule to be reported, got %d results", len(sink.results))
+	}
+
+	// Rules not started still pair their finished event with a started one
+	expectedTypes := []ScanEventType{ScanEventRuleStarted, ScanEventRuleFinished}
+	for index, result := range sink.results {
+		if result.Status != CheckResultError {
+			t.Errorf("Rule %d: expected ERROR, got %s", index, result.Status)
+		}
+		if types := sink.eventTypesFor(index); !reflect.DeepEqual(types, expectedTypes) {
+			t.Errorf("Rule %d: expected events %v, got %v", index, expectedTypes, types)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InputSnapshotStats reports how input fetches were served during a scan
+type InputSnapshotStats struct {
+	// Hits is the number of inputs served from data already fetched in the scan
+	Hits int `json:"hits"`
+
+	// Misses is the number of inputs that had to be fetched
+	Misses int `json:"misses"`
+}
+
+// inputFetchFunc fetches the data for a single input
+type inputFetchFunc func(ctx context.Context, rule Rule, input Input) (interface{}, []string, error)
+
+// inputSnapshot holds every input fetched during a single scan. Inputs are
+// keyed by their normalized specification so that rules declaring the same
+// input under different names share a single fetch. Fetched data is shared
+// between rules and must be treated as read-only.
+type inputSnapshot struct {
+	fetch inputFetchFunc
+
+	mu      sync.Mutex
+	entries map[string]*snapshotEntry
+	stats   InputSnapshotStats
+	fetched []InputStatistics
+}
+
+// snapshotEntry is the fetch result for one normalized input; ready is
+// closed once the fetch has completed
+type snapshotEntry struct {
+	ready    chan struct{}
+	data     interface{}
+	warnings []string
+	err      error
+
+	// digest is the content hash of data, computed on first use
+	digestOnce sync.Once
+	digest     string
+	digestErr  error
+}
+
+// newInputSnapshot creates an empty snapshot backed by the given fetch function
+func newInputSnapshot(fetch inputFetchFunc) *inputSnapshot {
+	return &inputSnapshot{
+		fetch:   fetch,
+		entries: make(map[string]*snapshotEntry),
+	}
+}
+
+// Get returns the data for an input, fetching it on first use. Concurrent
+// callers asking for the same input wait for the fetch in flight.
+func (s *inputSnapshot) Get(ctx context.Context, rule Rule, input Input) (interface{}, []string, error) {
+	key := snapshotKey(input)
+
+	s.mu.Lock()
+	if key == "" {
+		// Inputs that cannot be normalized are never shared
+		s.stats.Misses++
+		s.mu.Unlock()
+		return s.fetchAndRecord(ctx, rule, input)
+	}
+
+	for {
+		entry, ok := s.entries[key]
+		if !ok {
+			break
+		}
+		s.stats.Hits++
+		s.mu.Unlock()
+
+		select {
+		case <-entry.ready:
+			if !isContextError(entry.err) {
+				return entry.data, entry.warnings, entry.err
+			}
+			// The fetch was interrupted by its caller's context; retry under ours
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+		s.mu.Lock()
+	}
+
+	entry := &snapshotEntry{ready: make(chan struct{})}
+	s.entries[key] = entry
+	s.stats.Misses++
+	s.mu.Unlock()
+
+	entry.data, entry.warnings, entry.err = s.fetchAndRecord(ctx, rule, input)
+	if isContextError(entry.err) {
+		// Interrupted fetches are not kept so that other rules can retry them
+		s.mu.Lock()
+		delete(s.entries, key)
+		s.mu.Unlock()
+	}
+	close(entry.ready)
+
+	return entry.data, entry.warnings, entry.err
+}
+
+// fetchAndRecord fetches an input and records statistics about the fetch.
+// Interrupted fetches are not recorded since they are retried.
+func (s *inputSnapshot) fetchAndRecord(ctx context.Context, rule Rule, input Input) (interface{}, []string, error) {
+	start := time.Now()
+	data, warnings, err := s.fetch(ctx, rule, input)
+	if isContextError(err) {
+		return data, warnings, err
+	}
+
+	statistics := InputStatistics{
+		Input:       input.Name(),
+		Source:      describeInputSource(input),
+		ObjectCount: objectCount(data),
+		Duration:    time.Since(start),
+	}
+	if err != nil {
+		statistics.Error = err.Error()
+	}
+	s.mu.Lock()
+	s.fetched = append(s.fetched, statistics)
+	s.mu.Unlock()
+	return data, warnings, err
+}
+
+// Inputs returns statistics about the inputs fetched so far, ordered by source
+func (s *inputSnapshot) Inputs() []InputStatistics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inputs := append([]InputStatistics{}, s.fetched...)
+	sort.SliceStable(inputs, func(i, j int) bool { return inputs[i].Source < inputs[j].Source })
+	return inputs
+}
+
+// Digest returns the content hash of the data fetched for input. Digests of
+// shared inputs are computed once per scan.
+func (s *inputSnapshot) Digest(input Input, data interface{}) (string, error) {
+	key := snapshotKey(input)
+
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	s.mu.Unlock()
+	if key == "" || !ok {
+		return digestData(data)
+	}
+
+	<-entry.ready
+	entry.digestOnce.Do(func() {
+		entry.digest, entry.digestErr = digestData(entry.data)
+	})
+	return entry.digest, entry.digestErr
+}
+
+// Stats returns the hit and miss counts recorded so far
+func (s *inputSnapshot) Stats() InputSnapshotStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// snapshotKey returns the key an input is shared under within a scan
+func snapshotKey(input Input) string {
+	key := inputKey(input)
+	if key != "" && IsOptionalInput(input) {
+		// Fetchers resolve missing optional inputs to a value, which must not
+		// be shared with rules requiring the input
+		key += "|optional"
+	}
+	return key
+}
+
+// inputKey returns the normalized key of an input specification. The input
+// name is deliberately left out since it only controls the CEL binding.
+// An empty key is returned for specifications that cannot be normalized.
+func inputKey(input Input) string {
+	switch input.Type() {
+	case InputTypeKubernetes:
+		spec, ok := input.Spec().(KubernetesInputSpec)
+		if !ok {
+			return ""
+		}
+		return strings.Join([]string{
+			string(InputTypeKubernetes),
+			spec.ApiGroup(),
+			spec.Version(),
+			strings.ToLower(spec.ResourceType()),
+			spec.Namespace(),
+			spec.Name(),
+		}, "|")
+
+	case InputTypeFile:
+		spec, ok := input.Spec().(FileInputSpec)
+		if !ok {
+			return ""
+		}
+		return strings.Join([]string{
+			string(InputTypeFile),
+			filepath.Clean(spec.Path()),
+			strings.ToLower(spec.Format()),
+			fmt.Sprintf("%t", spec.Recursive()),
+			fmt.Sprintf("%t", spec.CheckPermissions()),
+		}, "|")
+
+	case InputTypeSystem:
+		spec, ok := input.Spec().(SystemInputSpec)
+		if !ok {
+			return ""
+		}
+		return strings.Join([]string{
+			string(InputTypeSystem),
+			spec.ServiceName(),
+			spec.Command(),
+			fmt.Sprintf("%q", spec.Args()),
+		}, "|")
+
+	case InputTypeHTTP:
+		spec, ok := input.Spec().(HTTPInputSpec)
+		if !ok {
+			return ""
+		}
+		headers := make([]string, 0, len(spec.Headers()))
+		for name, value := range spec.Headers() {
+			headers = append(headers, fmt.Sprintf("%q=%q", strings.ToLower(name), value))
+		}
+		sort.Strings(headers)
+		return strings.Join([]string{
+			string(InputTypeHTTP),
+			strings.ToUpper(spec.Method()),
+			spec.URL(),
+			strings.Join(headers, ","),
+			fmt.Sprintf("%q", spec.Body()),
+		}, "|")
+
+	default:
+		return ""
+	}
+}
+
+// singleInputRule narrows a rule down to one of its inputs so that it can be
+// handed to a ResourceFetcher
+type singleInputRule struct {
+	Rule
+	input Input
+}
+
+// Inputs returns the single input being fetched
+func (r *singleInputRule) Inputs() []Input { return []Input{r.input} }
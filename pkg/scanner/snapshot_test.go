@@ -0,0 +1,163 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInputKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		a         Input
+		b         Input
+		sameInput bool
+	}{
+		{
+			name:      "same Kubernetes spec under different names",
+			a:         NewKubernetesInput("pods", "", "v1", "pods", "default", ""),
+			b:         NewKubernetesInput("allPods", "", "v1", "Pods", "default", ""),
+			sameInput: true,
+		},
+		{
+			name:      "different namespaces",
+			a:         NewKubernetesInput("pods", "", "v1", "pods", "default", ""),
+			b:         NewKubernetesInput("pods", "", "v1", "pods", "kube-system", ""),
+			sameInput: false,
+		},
+		{
+			name:      "different versions",
+			a:         NewKubernetesInput("deployments", "apps", "v1", "deployments", "", ""),
+			b:         NewKubernetesInput("deployments", "apps", "v1beta1", "deployments", "", ""),
+			sameInput: false,
+		},
+		{
+			name:      "same file path written differently",
+			a:         NewFileInput("config", "/etc/app/config.yaml", "yaml", false, false),
+			b:         NewFileInput("cfg", "/etc/app/../app/config.yaml", "YAML", false, false),
+			sameInput: true,
+		},
+		{
+			name:      "file with and without permissions",
+			a:         NewFileInput("config", "/etc/app/config.yaml", "yaml", false, false),
+			b:         NewFileInput("config", "/etc/app/config.yaml", "yaml", false, true),
+			sameInput: false,
+		},
+		{
+			name:      "HTTP header order does not matter",
+			a:         NewHTTPInput("api", "https://example.com", "get", map[string]string{"A": "1", "B": "2"}, nil),
+			b:         NewHTTPInput("api", "https://example.com", "GET", map[string]string{"b": "2", "a": "1"}, nil),
+			sameInput: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyA, keyB := inputKey(tt.a), inputKey(tt.b)
+			if keyA == "" || keyB == "" {
+				t.Fatalf("Expected non-empty keys, got %q and %q", keyA, keyB)
+			}
+			if (keyA == keyB) != tt.sameInput {
+				t.Errorf("Expected same=%v, got keys %q and %q", tt.sameInput, keyA, keyB)
+			}
+		})
+	}
+}
+
+func TestInputSnapshot_FetchesOnce(t *testing.T) {
+	var calls atomic.Int32
+	snapshot := newInputSnapshot(func(ctx context.Context, rule Rule, input Input) (interface{}, []string, error) {
+		calls.Add(1)
+		time.Sleep(5 * time.Millisecond)
+		return map[string]interface{}{"items": []interface{}{}}, []string{"fetched " + input.Name()}, nil
+	})
+
+	rule := NewCelRule("rule", "true", nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			input := NewKubernetesInput(fmt.Sprintf("pods%d", i), "", "v1", "pods", "", "")
+			data, warnings, err := snapshot.Get(context.Background(), rule, input)
+			if err != nil || data == nil || len(warnings) != 1 {
+				t.Errorf("Unexpected snapshot result: %v, %v, %v", data, warnings, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 fetch, got %d", calls.Load())
+	}
+	if stats := snapshot.Stats(); stats.Misses != 1 || stats.Hits != 9 {
+		t.Errorf("Expected 1 miss and 9 hits, got %+v", stats)
+	}
+}
+
+func TestScanner_SharedInputs(t *testing.T) {
+	podsData := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"metadata": map[string]interface{}{"name": "a"}},
+		},
+	}
+
+	var rules []Rule
+	for i := 0; i < 8; i++ {
+		rule, err := NewRuleBuilder(fmt.Sprintf("rule-%d", i), RuleTypeCEL).
+			WithKubernetesInput("pods", "", "v1", "pods", "", "").
+			WithKubernetesInput("nodes", "", "v1", "nodes", "", "").
+			SetCelExpression("pods.items.size() == 1 && nodes.items.size() == 0").
+			BuildCelRule()
+		if err != nil {
+			t.Fatalf("Failed to build rule: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	fetcher := &countingFetcher{
+		data: map[string]interface{}{
+			"pods":  podsData,
+			"nodes": map[string]interface{}{"items": []interface{}{}},
+		},
+	}
+	scanner := NewScanner(fetcher, &TestLogger{t: t})
+
+	results, err := scanner.Scan(context.Background(), ScanConfig{Rules: rules, MaxConcurrency: 4})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, result := range results {
+		if result.Status != CheckResultPass {
+			t.Errorf("Rule %s: expected PASS, got %s (%v)", result.ID, result.Status, result.Warnings)
+		}
+	}
+
+	if calls := fetcher.calls.Load(); calls != 2 {
+		t.Errorf("Expected 2 fetches (one per distinct input), got %d", calls)
+	}
+
+	stats := scanner.LastInputSnapshotStats()
+	if stats.Misses != 2 || stats.Hits != 14 {
+		t.Errorf("Expected 2 misses and 14 hits, got %+v", stats)
+	}
+}
@@ -0,0 +1,297 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+// RuleState is the last result recorded for a rule together with the
+// fingerprint of everything the result was computed from
+type RuleState struct {
+	Fingerprint string      `json:"fingerprint"`
+	Result      CheckResult `json:"result"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+}
+
+// StateStore keeps the last result of each rule between scans so that rules
+// whose expression, variables and inputs did not change are not evaluated again
+type StateStore interface {
+	// Load returns the state recorded for a rule, if any
+	Load(ruleID string) (RuleState, bool, error)
+
+	// Save records the state of a rule, replacing any previous state
+	Save(ruleID string, state RuleState) error
+}
+
+// MemoryStateStore is a StateStore kept in memory, for scanners living as
+// long as the process. It is safe for concurrent use.
+type MemoryStateStore struct {
+	mu     sync.RWMutex
+	states map[string]RuleState
+}
+
+// NewMemoryStateStore creates an empty in-memory state store
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{states: make(map[string]RuleState)}
+}
+
+// Load returns the state recorded for a rule
+func (m *MemoryStateStore) Load(ruleID string) (RuleState, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	state, ok := m.states[ruleID]
+	return state, ok, nil
+}
+
+// Save records the state of a rule
+func (m *MemoryStateStore) Save(ruleID string, state RuleState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[ruleID] = state
+	return nil
+}
+
+// FileStateStore is a StateStore writing one JSON file per rule to a
+// directory, so that state survives restarts. Files are replaced atomically.
+type FileStateStore struct {
+	dir string
+}
+
+// NewFileStateStore creates a state store in dir, creating the directory if needed
+func NewFileStateStore(dir string) (*FileStateStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory %s: %w", dir, err)
+	}
+	return &FileStateStore{dir: dir}, nil
+}
+
+// Load reads the state recorded for a rule
+func (f *FileStateStore) Load(ruleID string) (RuleState, bool, error) {
+	data, err := os.ReadFile(f.path(ruleID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return RuleState{}, false, nil
+	}
+	if err != nil {
+		return RuleState{}, false, fmt.Errorf("failed to read state of rule %s: %w", ruleID, err)
+	}
+
+	var state RuleState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return RuleState{}, false, fmt.Errorf("failed to parse state of rule %s: %w", ruleID, err)
+	}
+	return state, true, nil
+}
+
+// Save writes the state of a rule
+func (f *FileStateStore) Save(ruleID string, state RuleState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state of rule %s: %w", ruleID, err)
+	}
+
+	tmp, err := os.CreateTemp(f.dir, ".state-*")
+	if err != nil {
+		return fmt.Errorf("failed to write state of rule %s: %w", ruleID, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state of rule %s: %w", ruleID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state of rule %s: %w", ruleID, err)
+	}
+	if err := os.Rename(tmp.Name(), f.path(ruleID)); err != nil {
+		return fmt.Errorf("failed to write state of rule %s: %w", ruleID, err)
+	}
+	return nil
+}
+
+// path returns the state file of a rule. Rule IDs are hashed since they may
+// contain characters that are not valid in file names.
+func (f *FileStateStore) path(ruleID string) string {
+	sum := sha256.Sum256([]byte(ruleID))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// stateFormatVersion is part of every fingerprint so that states recorded by
+// an incompatible version of the scanner are never reused
+const stateFormatVersion = "1"
+
+// ruleFingerprint hashes everything the result of a rule depends on: its
+// expressions and policies, the CEL environment options of the scanner, the
+// variables it mentions, the content of its inputs and the warnings raised
+// while fetching them
+func (s *Scanner) ruleFingerprint(run *scanRun, rule CelRule, resourceMap map[string]interface{}, warnings []string) (string, error) {
+	config := run.config
+	h := sha256.New()
+	write := func(values ...string) {
+		for _, value := range values {
+			h.Write([]byte(value))
+			h.Write([]byte{0})
+		}
+	}
+
+	write(stateFormatVersion, string(rule.Type()), rule.Expression())
+	write("env", s.envFingerprint)
+	expressions := []string{rule.Expression()}
+	if applicable, ok := rule.(ApplicableRule); ok {
+		write("applicability", applicable.ApplicabilityExpression(), applicable.NotApplicableReason())
+		expressions = append(expressions, applicable.ApplicabilityExpression())
+	}
+	if perObject, ok := rule.(PerObjectRule); ok {
+		write("forEach", perObject.ForEachInput())
+	}
+	if message := messageExpression(rule); message != "" {
+		write("message", message)
+		expressions = append(expressions, message)
+	}
+	write("missingData", string(missingDataPolicy(rule)))
+	write("fetchFailure", string(config.FetchFailurePolicy), fmt.Sprintf("cost=%d", config.CostLimit))
+	if config.Explain != nil {
+		write("explain", fmt.Sprintf("offenders=%d", config.Explain.maxOffenders()))
+	}
+
+	variables := make([]CelVariable, 0, len(config.Variables))
+	for _, variable := range config.Variables {
+		for _, expression := range expressions {
+			if mentionsIdentifier(expression, variable.Name()) {
+				variables = append(variables, variable)
+				break
+			}
+		}
+	}
+	sort.Slice(variables, func(i, j int) bool { return variables[i].Name() < variables[j].Name() })
+	for _, variable := range variables {
+		write("variable", variable.Name(), string(variableType(variable)), variable.Value())
+	}
+
+	inputs := append([]Input(nil), rule.Inputs()...)
+	sort.Slice(inputs, func(i, j int) bool { return inputs[i].Name() < inputs[j].Name() })
+	for _, input := range inputs {
+		data, ok := resourceMap[input.Name()]
+		if !ok {
+			write("input", input.Name(), inputKey(input), "unbound")
+			continue
+		}
+		digest, err := run.snapshot.Digest(input, data)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash input %s: %w", input.Name(), err)
+		}
+		write("input", input.Name(), inputKey(input), digest)
+	}
+
+	write("warnings")
+	write(warnings...)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// celEnvFingerprint hashes the libraries and function signatures of an
+// environment built with opts. Function implementations cannot be hashed:
+// state stores must be reset when a function changes behavior but not
+// signature.
+func celEnvFingerprint(opts []cel.EnvOption) string {
+	if len(opts) == 0 {
+		return ""
+	}
+	env, err := cel.NewEnv(append([]cel.EnvOption{cel.StdLib(), cel.OptionalTypes()}, opts...)...)
+	if err != nil {
+		// Rules fail to compile in this environment and are never recorded
+		return "invalid"
+	}
+
+	var signatures []string
+	for _, library := range env.Libraries() {
+		signatures = append(signatures, "library:"+library)
+	}
+	for name, function := range env.Functions() {
+		for _, overload := range function.OverloadDecls() {
+			args := make([]string, 0, len(overload.ArgTypes()))
+			for _, arg := range overload.ArgTypes() {
+				args = append(args, arg.String())
+			}
+			signatures = append(signatures, fmt.Sprintf("function:%s/%s(%s)%s",
+				name, overload.ID(), strings.Join(args, ","), overload.ResultType()))
+		}
+	}
+	sort.Strings(signatures)
+
+	sum := sha256.Sum256([]byte(strings.Join(signatures, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// digestData returns the content hash of fetched input data. Maps are
+// encoded with sorted keys, so equal data always has the same digest.
+func digestData(data interface{}) (string, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cacheable reports whether a result may be reused by later scans. Errors
+// are often transient, so rules that errored are always evaluated again.
+func cacheable(result CheckResult) bool {
+	return result.Status != CheckResultError && len(result.FetchErrors) == 0
+}
+
+// lookupState returns the previous result of a rule when it was computed
+// from the same fingerprint
+func (s *Scanner) lookupState(rule Rule, fingerprint string) (CheckResult, bool) {
+	state, ok, err := s.stateStore.Load(rule.Identifier())
+	if err != nil {
+		s.logger.Warn("Rule %s: %v", rule.Identifier(), err)
+		return CheckResult{}, false
+	}
+	if !ok || state.Fingerprint != fingerprint {
+		return CheckResult{}, false
+	}
+
+	result := state.Result
+	result.ID = rule.Identifier()
+	result.Cached = true
+	return result, true
+}
+
+// recordState stores the result of a rule for later scans
+func (s *Scanner) recordState(rule Rule, fingerprint string, result CheckResult) {
+	if !cacheable(result) {
+		return
+	}
+	result.Cached = false
+	state := RuleState{Fingerprint: fingerprint, Result: result, UpdatedAt: time.Now()}
+	if err := s.stateStore.Save(rule.Identifier(), state); err != nil {
+		s.logger.Warn("Rule %s: %v", rule.Identifier(), err)
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestScanner_IncrementalScan(t *testing.T) {
+	rule, err := NewRuleBuilder("enough-pods", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		SetCelExpression("pods.items.size() >= minPods").
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+	broken, err := NewRuleBuilder("broken", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		SetCelExpression("pods.items[5].metadata.name != ''").
+		WithMissingDataPolicy(MissingDataPolicyError).
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+
+	fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(2)}}
+	store := NewMemoryStateStore()
+	scanner := NewScanner(fetcher, &TestLogger{t: t}).WithStateStore(store)
+	minPods := NewCelVariable("minPods", "2", VariableTypeInt)
+	unrelated := NewCelVariable("unrelated", "a", VariableTypeString)
+
+	scan := func(variables ...CelVariable) []CheckResult {
+		t.Helper()
+		results, err := scanner.Scan(context.Background(), ScanConfig{
+			Rules:     []Rule{rule, broken},
+			Variables: variables,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return results
+	}
+
+	first := scan(minPods, unrelated)
+	if first[0].Status != CheckResultPass || first[0].Cached {
+		t.Fatalf("Expected an evaluated PASS, got %+v", first[0])
+	}
+	if _, ok, _ := store.Load("broken"); ok {
+		t.Errorf("Expected ERROR results not to be recorded")
+	}
+
+	second := scan(minPods, NewCelVariable("unrelated", "b", VariableTypeString))
+	if second[0].Status != CheckResultPass || !second[0].Cached {
+		t.Errorf("Expected the previous result to be reused, got %+v", second[0])
+	}
+	if second[1].Cached {
+		t.Errorf("Expected the erroring rule to be evaluated again")
+	}
+
+	third := scan(NewCelVariable("minPods", "3", VariableTypeInt))
+	if third[0].Status != CheckResultFail || third[0].Cached {
+		t.Errorf("Expected a changed variable to re-evaluate the rule, got %+v", third[0])
+	}
+
+	fetcher.data = map[string]interface{}{"pods": podList(3)}
+	fourth := scan(NewCelVariable("minPods", "3", VariableTypeInt))
+	if fourth[0].Status != CheckResultPass || fourth[0].Cached {
+		t.Errorf("Expected changed input data to re-evaluate the rule, got %+v", fourth[0])
+	}
+}
+
+func TestScanner_IncrementalScanEnvOptions(t *testing.T) {
+	rule, err := NewRuleBuilder("pods", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		SetCelExpression("pods.items.size() == 2").
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+	identity := func(name string, argType *cel.Type) cel.EnvOption {
+		return cel.Function(name, cel.Overload(name+"_overload", []*cel.Type{argType}, argType,
+			cel.UnaryBinding(func(val ref.Val) ref.Val { return val })))
+	}
+
+	fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(2)}}
+	store := NewMemoryStateStore()
+	// Each scanner stands for a separate process sharing the store
+	scan := func(opts ...cel.EnvOption) CheckResult {
+		t.Helper()
+		scanner := NewScanner(fetcher, &TestLogger{t: t}).WithStateStore(store).WithCelEnvOptions(opts...)
+		results, err := scanner.Scan(context.Background(), ScanConfig{Rules: []Rule{rule}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return results[0]
+	}
+
+	if result := scan(identity("same", cel.StringType)); result.Cached {
+		t.Fatalf("Expected the first scan to evaluate the rule")
+	}
+	if result := scan(identity("same", cel.StringType)); !result.Cached {
+		t.Errorf("Expected identical environment options to reuse the result")
+	}
+	if result := scan(identity("same", cel.IntType)); result.Cached {
+		t.Errorf("Expected a changed function signature to re-evaluate the rule")
+	}
+	if result := scan(); result.Cached {
+		t.Errorf("Expected removed environment options to re-evaluate the rule")
+	}
+}
+
+func TestFileStateStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStateStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	if _, ok, err := store.Load("rule/with:odd chars"); ok || err != nil {
+		t.Fatalf("Expected no state for an unknown rule, got %v %v", ok, err)
+	}
+
+	state := RuleState{
+		Fingerprint: "abc",
+		Result:      CheckResult{ID: "rule/with:odd chars", Status: CheckResultFail, Warnings: []string{"w"}},
+		UpdatedAt:   time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+	}
+	if err := store.Save("rule/with:odd chars", state); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	// State is read back by a new store on the same directory
+	reopened, err := NewFileStateStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	loaded, ok, err := reopened.Load("rule/with:odd chars")
+	if err != nil || !ok {
+		t.Fatalf("Expected saved state, got %v %v", ok, err)
+	}
+	if loaded.Fingerprint != "abc" || loaded.Result.Status != CheckResultFail || !loaded.UpdatedAt.Equal(state.UpdatedAt) {
+		t.Errorf("Unexpected state: %+v", loaded)
+	}
+}
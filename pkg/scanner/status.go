@@ -0,0 +1,166 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// statusPrecedence orders statuses from the one that wins an aggregation to
+// the one that loses it
+var statusPrecedence = []CheckResultStatus{
+	CheckResultFail,
+	CheckResultInconsistent,
+	CheckResultError,
+	CheckResultManual,
+	CheckResultWaived,
+	CheckResultPass,
+	CheckResultInfo,
+	CheckResultNotApplicable,
+	CheckResultSkipped,
+}
+
+// StatusPrecedence returns the rank of a status when results are aggregated;
+// lower ranks win. Unknown statuses rank after every known status.
+func StatusPrecedence(status CheckResultStatus) int {
+	if status == StatusSkip {
+		status = CheckResultSkipped
+	}
+	for rank, candidate := range statusPrecedence {
+		if candidate == status {
+			return rank
+		}
+	}
+	return len(statusPrecedence)
+}
+
+// AggregateStatuses combines the statuses of the parts of a check, such as the
+// objects of a per-object rule or the rules of a control, into one status.
+// The status with the lowest precedence rank wins:
+// FAIL > INCONSISTENT > ERROR > MANUAL > WAIVED > PASS > INFO > NOT-APPLICABLE > SKIPPED.
+// So a check passes when its parts pass, are informational or do not apply,
+// and is only NOT-APPLICABLE or SKIPPED when all its parts are.
+// An empty list aggregates to an empty status.
+func AggregateStatuses(statuses []CheckResultStatus) CheckResultStatus {
+	var aggregate CheckResultStatus
+	for _, status := range statuses {
+		if aggregate == "" || StatusPrecedence(status) < StatusPrecedence(aggregate) {
+			aggregate = status
+		}
+	}
+	return aggregate
+}
+
+// ConsistentStatus combines the statuses of the same rule evaluated on
+// several nodes. Nodes where the rule does not apply or was skipped are
+// ignored; if the remaining nodes agree their status is returned, an error on
+// any node makes the result ERROR, and any other disagreement is INCONSISTENT.
+func ConsistentStatus(statuses []CheckResultStatus) CheckResultStatus {
+	var decisive []CheckResultStatus
+	for _, status := range statuses {
+		switch status {
+		case CheckResultNotApplicable, CheckResultSkipped, StatusSkip:
+		case CheckResultError:
+			return CheckResultError
+		default:
+			decisive = append(decisive, status)
+		}
+	}
+	if len(decisive) == 0 {
+		return AggregateStatuses(statuses)
+	}
+	for _, status := range decisive[1:] {
+		if status != decisive[0] {
+			return CheckResultInconsistent
+		}
+	}
+	return decisive[0]
+}
+
+// ParseCheckResultStatus parses a status case-insensitively. The legacy
+// ScanStatus value "SKIP" and "NOT_APPLICABLE" are accepted.
+func ParseCheckResultStatus(value string) (CheckResultStatus, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(value))
+	switch normalized {
+	case string(StatusSkip):
+		return CheckResultSkipped, nil
+	case "NOT_APPLICABLE":
+		return CheckResultNotApplicable, nil
+	}
+	for _, status := range statusPrecedence {
+		if string(status) == normalized {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("unknown check result status: %q", value)
+}
+
+// checkKind returns how a rule is checked
+func checkKind(rule Rule) CheckKind {
+	if kindRule, ok := rule.(CheckKindRule); ok && kindRule.CheckKind() != "" {
+		return kindRule.CheckKind()
+	}
+	return CheckKindAutomated
+}
+
+// unevaluatedResult returns the result of rules that are not evaluated:
+// rules deselected by the scan configuration and manual rules
+func unevaluatedResult(config ScanConfig, rule Rule) (CheckResult, bool) {
+	for _, id := range config.SkipRules {
+		if id == rule.Identifier() {
+			return CheckResult{
+				ID:       rule.Identifier(),
+				Status:   CheckResultSkipped,
+				Metadata: CheckResultMetadata{},
+				Warnings: []string{},
+				Reason:   "rule deselected from the scan",
+			}, true
+		}
+	}
+
+	if checkKind(rule) == CheckKindManual {
+		return CheckResult{
+			ID:       rule.Identifier(),
+			Status:   CheckResultManual,
+			Metadata: CheckResultMetadata{},
+			Warnings: []string{},
+			Reason:   "rule requires manual verification",
+		}, true
+	}
+
+	return CheckResult{}, false
+}
+
+// finalizeStatus reports the outcome of informational rules as INFO and
+// failures of waived rules as WAIVED
+func finalizeStatus(config ScanConfig, rule Rule, result CheckResult) CheckResult {
+	passed := result.Status == CheckResultPass
+	if checkKind(rule) == CheckKindInformational && (passed || result.Status == CheckResultFail) {
+		result.Status = CheckResultInfo
+		result.Reason = fmt.Sprintf("informational check evaluated to %t", passed)
+		return result
+	}
+
+	if justification, ok := config.Waivers[rule.Identifier()]; ok && result.Status == CheckResultFail {
+		result.Status = CheckResultWaived
+		result.Reason = "waived"
+		if justification != "" {
+			result.Reason += ": " + justification
+		}
+	}
+	return result
+}
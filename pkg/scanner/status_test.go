@@ -0,0 +1,154 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAggregateStatuses(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []CheckResultStatus
+		expected CheckResultStatus
+	}{
+		{"empty", nil, ""},
+		{"fail wins", []CheckResultStatus{CheckResultPass, CheckResultError, CheckResultFail, CheckResultWaived}, CheckResultFail},
+		{"error over manual", []CheckResultStatus{CheckResultManual, CheckResultError, CheckResultPass}, CheckResultError},
+		{"waived over pass", []CheckResultStatus{CheckResultPass, CheckResultWaived}, CheckResultWaived},
+		{"pass over info and not applicable", []CheckResultStatus{CheckResultInfo, CheckResultNotApplicable, CheckResultPass}, CheckResultPass},
+		{"all not applicable", []CheckResultStatus{CheckResultNotApplicable, CheckResultSkipped}, CheckResultNotApplicable},
+		{"all skipped", []CheckResultStatus{CheckResultSkipped, CheckResultSkipped}, CheckResultSkipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AggregateStatuses(tt.statuses); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestConsistentStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []CheckResultStatus
+		expected CheckResultStatus
+	}{
+		{"agreeing nodes", []CheckResultStatus{CheckResultPass, CheckResultPass}, CheckResultPass},
+		{"disagreeing nodes", []CheckResultStatus{CheckResultPass, CheckResultFail}, CheckResultInconsistent},
+		{"error on a node", []CheckResultStatus{CheckResultPass, CheckResultFail, CheckResultError}, CheckResultError},
+		{"not applicable nodes ignored", []CheckResultStatus{CheckResultFail, CheckResultNotApplicable, CheckResultFail}, CheckResultFail},
+		{"no decisive node", []CheckResultStatus{CheckResultNotApplicable, CheckResultSkipped}, CheckResultNotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConsistentStatus(tt.statuses); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseCheckResultStatus(t *testing.T) {
+	valid := map[string]CheckResultStatus{
+		"PASS":           CheckResultPass,
+		"waived":         CheckResultWaived,
+		" Manual ":       CheckResultManual,
+		"SKIP":           CheckResultSkipped,
+		"not_applicable": CheckResultNotApplicable,
+		"NOT-APPLICABLE": CheckResultNotApplicable,
+	}
+	for value, expected := range valid {
+		status, err := ParseCheckResultStatus(value)
+		if err != nil || status != expected {
+			t.Errorf("ParseCheckResultStatus(%q) = %q, %v; expected %q", value, status, err, expected)
+		}
+	}
+
+	if _, err := ParseCheckResultStatus("MAYBE"); err == nil {
+		t.Error("Expected an error for an unknown status")
+	}
+	if StatusSkip != "SKIP" {
+		t.Errorf("Expected the deprecated StatusSkip to keep its serialized value, got %q", StatusSkip)
+	}
+	if StatusPrecedence(StatusSkip) != StatusPrecedence(CheckResultSkipped) {
+		t.Error("Expected the deprecated StatusSkip to rank like CheckResultSkipped")
+	}
+	if got := ConsistentStatus([]CheckResultStatus{StatusSkip, CheckResultPass}); got != CheckResultPass {
+		t.Errorf("Expected nodes with the deprecated StatusSkip to be ignored, got %q", got)
+	}
+}
+
+func TestScanner_RuleStatuses(t *testing.T) {
+	build := func(builder *RuleBuilder) Rule {
+		t.Helper()
+		rule, err := builder.Build()
+		if err != nil {
+			t.Fatalf("Failed to build rule: %v", err)
+		}
+		return rule
+	}
+	podRule := func(id, expression string) *RuleBuilder {
+		return NewRuleBuilder(id, RuleTypeCEL).
+			WithKubernetesInput("pods", "", "v1", "pods", "", "").
+			SetCelExpression(expression)
+	}
+
+	rules := []Rule{
+		build(NewRuleBuilder("manual", RuleTypeCEL).WithCheckKind(CheckKindManual)),
+		build(podRule("skipped", "pods.items.size() > 0")),
+		build(podRule("informational", "pods.items.size() > 5").WithCheckKind(CheckKindInformational)),
+		build(podRule("waived-failure", "pods.items.size() > 5")),
+		build(podRule("waived-pass", "pods.items.size() > 0")),
+	}
+
+	fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(1)}}
+	results, err := NewScanner(fetcher, &TestLogger{t: t}).Scan(context.Background(), ScanConfig{
+		Rules:     rules,
+		SkipRules: []string{"skipped"},
+		Waivers:   map[string]string{"waived-failure": "accepted until Q3", "waived-pass": ""},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		status CheckResultStatus
+		reason string
+	}{
+		{CheckResultManual, "rule requires manual verification"},
+		{CheckResultSkipped, "rule deselected from the scan"},
+		{CheckResultInfo, "informational check evaluated to false"},
+		{CheckResultWaived, "waived: accepted until Q3"},
+		{CheckResultPass, ""},
+	}
+	for i, want := range expected {
+		if results[i].Status != want.status || results[i].Reason != want.reason {
+			t.Errorf("Rule %s: expected %s (%q), got %s (%q)", results[i].ID, want.status, want.reason, results[i].Status, results[i].Reason)
+		}
+	}
+
+	if _, err := NewRuleBuilder("unknown-kind", RuleTypeCEL).WithCheckKind("sometimes").Build(); err == nil {
+		t.Error("Expected an error for an unsupported check kind")
+	}
+	if result := NewRuleValidator(&TestLogger{t: t}).ValidateRule(rules[0]); !result.Valid {
+		t.Errorf("Expected manual rules to be valid, got %v", result.Issues)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// SummarySlowestRules is the number of rules listed in ScanSummary.SlowestRules
+const SummarySlowestRules = 10
+
+// ScanSummary describes a whole scan: how its rules ended and where the time
+// went. Durations are encoded in JSON as nanoseconds, like ScanConfig timeouts.
+type ScanSummary struct {
+	ScanID        string                    `json:"scanId,omitempty"`
+	StartTime     time.Time                 `json:"startTime"`
+	EndTime       time.Time                 `json:"endTime"`
+	Duration      time.Duration             `json:"duration"`      // Wall-clock duration of the scan
+	RuleCount     int                       `json:"ruleCount"`     // Number of rules in the scan
+	StatusCounts  map[CheckResultStatus]int `json:"statusCounts"`  // Number of results with each status
+	CachedResults int                       `json:"cachedResults"` // Results reused from the state store
+	Timings       PhaseTimings              `json:"timings"`       // Time spent in each phase, summed over rules
+	Rules         []RuleStatistics          `json:"rules"`         // Per-rule statistics in ScanConfig.Rules order
+	SlowestRules  []RuleStatistics          `json:"slowestRules"`  // Rules that took the longest, slowest first
+	Inputs        []InputStatistics         `json:"inputs"`        // Inputs fetched during the scan, ordered by source
+	InputSnapshot InputSnapshotStats        `json:"inputSnapshot"` // How input fetches were shared between rules
+}
+
+// PhaseTimings splits the time spent on a rule between its phases. Rules
+// processed in parallel overlap, so timings summed over rules can exceed the
+// duration of the scan.
+type PhaseTimings struct {
+	// Fetch is the time spent waiting for inputs, including inputs fetched by
+	// another rule of the scan
+	Fetch time.Duration `json:"fetch"`
+
+	// Compile is the time spent compiling expressions; programs reused from
+	// the program cache take no time
+	Compile time.Duration `json:"compile"`
+
+	// Eval is the remaining time, mostly spent evaluating expressions
+	Eval time.Duration `json:"eval"`
+
+	// Total is the time from the start of the rule to its result
+	Total time.Duration `json:"total"`
+}
+
+// add accumulates other into t
+func (t *PhaseTimings) add(other PhaseTimings) {
+	t.Fetch += other.Fetch
+	t.Compile += other.Compile
+	t.Eval += other.Eval
+	t.Total += other.Total
+}
+
+// RuleStatistics describes how a single rule was processed
+type RuleStatistics struct {
+	ID      string            `json:"id"`
+	Status  CheckResultStatus `json:"status"`
+	Cached  bool              `json:"cached,omitempty"`
+	Timings PhaseTimings      `json:"timings"`
+}
+
+// InputStatistics describes an input fetched during the scan. Inputs shared
+// by several rules are fetched, and listed, once.
+type InputStatistics struct {
+	Input       string        `json:"input"`           // Name of the input in the rule that fetched it
+	Source      string        `json:"source"`          // What the input requested
+	ObjectCount int           `json:"objectCount"`     // Items of a list, 1 for a single object, 0 for absent data
+	Duration    time.Duration `json:"duration"`        // Time taken by the fetch
+	Error       string        `json:"error,omitempty"` // Why the fetch failed
+}
+
+// ruleTimer measures the phases of a rule as it is processed
+type ruleTimer struct {
+	start   time.Time
+	timings PhaseTimings
+}
+
+// startRuleTimer starts measuring a rule
+func startRuleTimer() *ruleTimer {
+	return &ruleTimer{start: time.Now()}
+}
+
+// measureFetch adds the time until the returned function is called to the
+// fetch phase. A nil timer measures nothing.
+func (t *ruleTimer) measureFetch() func() {
+	if t == nil {
+		return func() {}
+	}
+	return measure(&t.timings.Fetch)
+}
+
+// measureCompile adds the time until the returned function is called to the
+// compile phase. A nil timer measures nothing.
+func (t *ruleTimer) measureCompile() func() {
+	if t == nil {
+		return func() {}
+	}
+	return measure(&t.timings.Compile)
+}
+
+// measure adds the time until the returned function is called to phase
+func measure(phase *time.Duration) func() {
+	start := time.Now()
+	return func() { *phase += time.Since(start) }
+}
+
+// stop ends the measurement, attributing the time not spent fetching or
+// compiling to evaluation
+func (t *ruleTimer) stop() PhaseTimings {
+	timings := t.timings
+	timings.Total = time.Since(t.start)
+	timings.Eval = timings.Total - timings.Fetch - timings.Compile
+	if timings.Eval < 0 {
+		timings.Eval = 0
+	}
+	return timings
+}
+
+// summarize builds the summary of a completed scan run
+func (r *scanRun) summarize() ScanSummary {
+	summary := ScanSummary{
+		ScanID:        r.environment.ScanID,
+		StartTime:     r.environment.StartTime,
+		EndTime:       r.environment.EndTime,
+		Duration:      r.environment.EndTime.Sub(r.environment.StartTime),
+		RuleCount:     len(r.rules),
+		StatusCounts:  make(map[CheckResultStatus]int),
+		CachedResults: int(r.reused.Load()),
+		Rules:         r.rules,
+		Inputs:        r.snapshot.Inputs(),
+		InputSnapshot: r.snapshot.Stats(),
+	}
+	for _, rule := range r.rules {
+		summary.StatusCounts[rule.Status]++
+		summary.Timings.add(rule.Timings)
+	}
+
+	slowest := append([]RuleStatistics(nil), r.rules...)
+	sort.SliceStable(slowest, func(i, j int) bool { return slowest[i].Timings.Total > slowest[j].Timings.Total })
+	if len(slowest) > SummarySlowestRules {
+		slowest = slowest[:SummarySlowestRules]
+	}
+	summary.SlowestRules = slowest
+	return summary
+}
+
+// SaveSummary writes a scan summary as indented JSON, to be stored next to
+// the results written by SaveResults
+func SaveSummary(filePath string, summary ScanSummary) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create summary file %s: %v", filePath, err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(summary); err != nil {
+		return fmt.Errorf("failed to encode summary to JSON: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func summaryRules(t *testing.T) []Rule {
+	t.Helper()
+	expressions := map[string]string{
+		"passes": "pods.items.size() == 3",
+		"fails":  "pods.items.size() == 0",
+		"errors": "pods.items[0].metadata.name + 1 == 2",
+	}
+	var rules []Rule
+	for _, id := range []string{"passes", "fails", "errors"} {
+		rule, err := NewRuleBuilder(id, RuleTypeCEL).
+			WithKubernetesInput("pods", "", "v1", "pods", "", "").
+			SetCelExpression(expressions[id]).
+			BuildCelRule()
+		if err != nil {
+			t.Fatalf("Failed to build rule: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+	manual, err := NewRuleBuilder("manual", RuleTypeCEL).WithCheckKind(CheckKindManual).BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+	return append(rules, manual)
+}
+
+func TestScanner_ScanWithSummary(t *testing.T) {
+	fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(3)}, delay: 20 * time.Millisecond}
+	s := NewScanner(fetcher, &TestLogger{t: t}).WithProgramCache(nil)
+	results, summary, err := s.ScanWithSummary(context.Background(), ScanConfig{Rules: summaryRules(t), ScanID: "summary"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+
+	if summary.ScanID != "summary" || summary.RuleCount != 4 || summary.Duration <= 0 {
+		t.Errorf("Unexpected summary header: %+v", summary)
+	}
+	expectedCounts := map[CheckResultStatus]int{CheckResultPass: 1, CheckResultFail: 1, CheckResultError: 1, CheckResultManual: 1}
+	for status, count := range expectedCounts {
+		if summary.StatusCounts[status] != count {
+			t.Errorf("Expected %d %s results, got %d", count, status, summary.StatusCounts[status])
+		}
+	}
+
+	// The input shared by the rules is fetched and listed once
+	if len(summary.Inputs) != 1 || summary.Inputs[0].ObjectCount != 3 || summary.Inputs[0].Source != "v1/pods" {
+		t.Fatalf("Unexpected inputs: %+v", summary.Inputs)
+	}
+	if summary.Inputs[0].Duration < 20*time.Millisecond {
+		t.Errorf("Expected the fetch duration to include the fetcher delay, got %s", summary.Inputs[0].Duration)
+	}
+	if summary.InputSnapshot.Misses != 1 || summary.InputSnapshot.Hits != 2 {
+		t.Errorf("Unexpected snapshot stats: %+v", summary.InputSnapshot)
+	}
+
+	first := summary.Rules[0]
+	if first.ID != "passes" || first.Status != CheckResultPass {
+		t.Fatalf("Expected rule statistics in rule order, got %+v", summary.Rules)
+	}
+	if first.Timings.Fetch < 20*time.Millisecond || first.Timings.Compile <= 0 {
+		t.Errorf("Expected fetch and compile time for the first rule, got %+v", first.Timings)
+	}
+	if first.Timings.Total != first.Timings.Fetch+first.Timings.Compile+first.Timings.Eval {
+		t.Errorf("Expected phases to add up to the total, got %+v", first.Timings)
+	}
+	if summary.Rules[3].Timings.Total != 0 {
+		t.Errorf("Expected no timings for the manual rule, got %+v", summary.Rules[3].Timings)
+	}
+	if summary.Timings.Total < first.Timings.Total {
+		t.Errorf("Expected scan timings to sum the rules, got %+v", summary.Timings)
+	}
+
+	if len(summary.SlowestRules) != 4 || summary.SlowestRules[0].ID != "passes" {
+		t.Errorf("Expected the rule that fetched the input to be the slowest, got %+v", summary.SlowestRules)
+	}
+	for i := 1; i < len(summary.SlowestRules); i++ {
+		if summary.SlowestRules[i].Timings.Total > summary.SlowestRules[i-1].Timings.Total {
+			t.Errorf("Expected slowest rules in decreasing order, got %+v", summary.SlowestRules)
+		}
+	}
+
+	if last := s.LastScanSummary(); last.ScanID != "summary" {
+		t.Errorf("Expected LastScanSummary to return the summary, got %+v", last)
+	}
+}
+
+func TestSaveSummary(t *testing.T) {
+	fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(3)}}
+	_, summary, err := NewScanner(fetcher, &TestLogger{t: t}).ScanWithSummary(context.Background(), ScanConfig{Rules: summaryRules(t)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "summary.json")
+	if err := SaveSummary(path, summary); err != nil {
+		t.Fatalf("Failed to save summary: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read summary: %v", err)
+	}
+
+	var decoded ScanSummary
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatalf("Failed to decode summary: %v", err)
+	}
+	if decoded.ScanID != summary.ScanID || decoded.StatusCounts[CheckResultFail] != 1 || len(decoded.Rules) != 4 {
+		t.Errorf("Summary did not round-trip: %+v", decoded)
+	}
+}
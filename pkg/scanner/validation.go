@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker"
 	"github.com/google/cel-go/checker/decls"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
@@ -47,8 +48,18 @@ const (
 
 	// ValidationErrorTypeGeneral represents a general compilation error
 	ValidationErrorTypeGeneral ValidationErrorType = "GENERAL_ERROR"
+
+	// ValidationErrorTypeCost represents an expression whose estimated cost exceeds the budget
+	ValidationErrorTypeCost ValidationErrorType = "COST_ERROR"
+
+	// ValidationErrorTypeVariable represents a variable whose value does not match its declared type
+	ValidationErrorTypeVariable ValidationErrorType = "VARIABLE_ERROR"
 )
 
+// DefaultEstimatedInputSize is the number of list items, map entries or
+// characters assumed for input values when estimating expression cost
+const DefaultEstimatedInputSize = 1000
+
 // ValidationIssue represents a single validation issue
 type ValidationIssue struct {
 	// Type is the type of validation error
@@ -90,7 +101,12 @@ type ValidationResult struct {
 
 // RuleValidator provides methods for validating rules
 type RuleValidator struct {
-	logger Logger
+	logger     Logger
+	envOptions []cel.EnvOption
+
+	// Expressions whose estimated worst-case cost exceeds costLimit are rejected
+	costLimit          uint64
+	estimatedInputSize uint64
 }
 
 // NewRuleValidator creates a new rule validator
@@ -99,17 +115,51 @@ func NewRuleValidator(logger Logger) *RuleValidator {
 		logger = DefaultLogger{}
 	}
 	return &RuleValidator{
-		logger: logger,
+		logger:             logger,
+		estimatedInputSize: DefaultEstimatedInputSize,
 	}
 }
 
+// WithCelEnvOptions adds CEL environment options (for example extension
+// libraries) to the validation environment, matching the scanner environment
+func (v *RuleValidator) WithCelEnvOptions(opts ...cel.EnvOption) *RuleValidator {
+	v.envOptions = opts
+	return v
+}
+
+// WithCostLimit rejects expressions whose estimated worst-case runtime cost
+// exceeds limit, typically the ScanConfig.CostLimit the rules will run with.
+// A limit of zero disables the check.
+func (v *RuleValidator) WithCostLimit(limit uint64) *RuleValidator {
+	v.costLimit = limit
+	return v
+}
+
+// WithEstimatedInputSize sets the size assumed for lists, maps and strings
+// read from inputs when estimating expression cost
+func (v *RuleValidator) WithEstimatedInputSize(size uint64) *RuleValidator {
+	v.estimatedInputSize = size
+	return v
+}
+
 // ValidateRule performs full validation of a rule
 func (v *RuleValidator) ValidateRule(rule Rule) ValidationResult {
+	return v.ValidateRuleWithVariables(rule, nil)
+}
+
+// ValidateRuleWithVariables validates a rule against the given variables,
+// declaring each with its type and checking that its value parses
+func (v *RuleValidator) ValidateRuleWithVariables(rule Rule, variables []CelVariable) ValidationResult {
 	result := ValidationResult{
 		Valid:  true,
 		Issues: []ValidationIssue{},
 	}
 
+	// Manual rules are never evaluated
+	if checkKind(rule) == CheckKindManual {
+		return result
+	}
+
 	// Only validate CEL rules for now
 	if rule.Type() != RuleTypeCEL {
 		result.Warnings = append(result.Warnings,
@@ -127,8 +177,14 @@ func (v *RuleValidator) ValidateRule(rule Rule) ValidationResult {
 		return result
 	}
 
-	// Create declarations for the rule's inputs
-	declsList := v.createDeclarationsForRule(rule)
+	// Variable values are checked before the expressions referencing them
+	if issues := v.ValidateVariables(variables); len(issues) > 0 {
+		result.Valid = false
+		result.Issues = append(result.Issues, issues...)
+	}
+
+	// Create declarations for the rule's inputs and variables
+	declsList := v.createDeclarationsForRule(rule, variables)
 
 	// Validate the CEL expression with declarations
 	issues := v.ValidateCELExpressionWithInputs(celRule.Expression(), declsList)
@@ -137,9 +193,57 @@ func (v *RuleValidator) ValidateRule(rule Rule) ValidationResult {
 		result.Issues = append(result.Issues, issues...)
 	}
 
+	// Validate the applicability condition against the same declarations
+	if applicable, ok := rule.(ApplicableRule); ok && applicable.ApplicabilityExpression() != "" {
+		issues := v.validateAuxiliaryExpression("Applicability expression", applicable.ApplicabilityExpression(), cel.BoolType, declsList)
+		if len(issues) > 0 {
+			result.Valid = false
+			result.Issues = append(result.Issues, issues...)
+		}
+	}
+
+	// The failure message is rendered from the same inputs and variables
+	if message, ok := rule.(MessageRule); ok && message.MessageExpression() != "" {
+		issues := v.validateAuxiliaryExpression("Message expression", message.MessageExpression(), cel.StringType, declsList)
+		if len(issues) > 0 {
+			result.Valid = false
+			result.Issues = append(result.Issues, issues...)
+		}
+	}
+
 	return result
 }
 
+// validateAuxiliaryExpression checks that an expression accompanying the
+// rule expression, such as its applicability condition or failure message,
+// compiles and evaluates to the expected type. Issues are prefixed with label.
+func (v *RuleValidator) validateAuxiliaryExpression(label, expression string, want *cel.Type, declarations []*expr.Decl) []ValidationIssue {
+	issues := v.ValidateCELExpressionWithInputs(expression, declarations)
+	if len(issues) == 0 {
+		env, err := v.createValidationEnvironment(declarations)
+		if err != nil {
+			return []ValidationIssue{{
+				Type:    ValidationErrorTypeGeneral,
+				Message: "Failed to create validation environment",
+				Details: err.Error(),
+			}}
+		}
+		ast, _ := env.Compile(expression)
+		outputType := ast.OutputType()
+		if !outputType.IsExactType(want) && !outputType.IsExactType(cel.DynType) {
+			issues = append(issues, ValidationIssue{
+				Type:    ValidationErrorTypeType,
+				Message: fmt.Sprintf("Expression must evaluate to a %s, got %s", want, outputType),
+			})
+		}
+	}
+
+	for i := range issues {
+		issues[i].Message = label + ": " + issues[i].Message
+	}
+	return issues
+}
+
 // ValidateCELExpressionWithInputs validates a CEL expression with optional declarations
 func (v *RuleValidator) ValidateCELExpressionWithInputs(expression string, declarations []*expr.Decl) []ValidationIssue {
 	issues := []ValidationIssue{}
@@ -156,20 +260,77 @@ func (v *RuleValidator) ValidateCELExpressionWithInputs(expression string, decla
 	}
 
 	// Compile the expression
-	compileIssues := v.compileCELForValidation(env, expression)
+	ast, compileIssues := v.compileCELForValidation(env, expression)
 	issues = append(issues, compileIssues...)
 
+	// Check the estimated cost against the budget
+	if len(compileIssues) == 0 && v.costLimit > 0 {
+		issues = append(issues, v.checkEstimatedCost(env, ast)...)
+	}
+
 	return issues
 }
 
+// checkEstimatedCost reports an issue when the worst-case cost of a compiled
+// expression exceeds the cost limit
+func (v *RuleValidator) checkEstimatedCost(env *cel.Env, ast *cel.Ast) []ValidationIssue {
+	estimate, err := env.EstimateCost(ast, inputSizeEstimator{size: v.estimatedInputSize})
+	if err != nil {
+		return []ValidationIssue{{
+			Type:    ValidationErrorTypeCost,
+			Message: "Failed to estimate expression cost",
+			Details: err.Error(),
+		}}
+	}
+
+	if estimate.Max <= v.costLimit {
+		return nil
+	}
+	return []ValidationIssue{{
+		Type:    ValidationErrorTypeCost,
+		Message: fmt.Sprintf("Estimated worst-case cost %d exceeds the cost limit of %d", estimate.Max, v.costLimit),
+		Details: fmt.Sprintf("Estimate assumes inputs hold at most %d items; reduce nested comprehensions or raise the limit", v.estimatedInputSize),
+	}}
+}
+
+// inputSizeEstimator bounds every value of unknown size by a fixed size
+type inputSizeEstimator struct {
+	size uint64
+}
+
+// EstimateSize returns the configured size for any value of unknown size
+func (e inputSizeEstimator) EstimateSize(element checker.AstNode) *checker.SizeEstimate {
+	return &checker.SizeEstimate{Min: 0, Max: e.size}
+}
+
+// EstimateCallCost defers to the default call cost estimates
+func (e inputSizeEstimator) EstimateCallCost(function, overloadID string, target *checker.AstNode, args []checker.AstNode) *checker.CallEstimate {
+	return nil
+}
+
 // ValidateCELExpression validates just the syntax of a CEL expression
 // without requiring input declarations
 func (v *RuleValidator) ValidateCELExpression(expression string) []ValidationIssue {
 	return v.ValidateCELExpressionWithInputs(expression, nil)
 }
 
-// createDeclarationsForRule creates CEL declarations from a rule's inputs
-func (v *RuleValidator) createDeclarationsForRule(rule Rule) []*expr.Decl {
+// ValidateVariables checks that every variable has a supported type and a
+// value that parses as that type
+func (v *RuleValidator) ValidateVariables(variables []CelVariable) []ValidationIssue {
+	var issues []ValidationIssue
+	for _, variable := range variables {
+		if _, err := ParseVariableValue(variable); err != nil {
+			issues = append(issues, ValidationIssue{
+				Type:    ValidationErrorTypeVariable,
+				Message: err.Error(),
+			})
+		}
+	}
+	return issues
+}
+
+// createDeclarationsForRule creates CEL declarations from a rule's inputs and variables
+func (v *RuleValidator) createDeclarationsForRule(rule Rule, variables []CelVariable) []*expr.Decl {
 	declsList := []*expr.Decl{}
 
 	// Add declarations for each input
@@ -178,6 +339,18 @@ func (v *RuleValidator) createDeclarationsForRule(rule Rule) []*expr.Decl {
 		declsList = append(declsList, decls.NewVar(input.Name(), decls.Dyn))
 	}
 
+	// Per-object rules also see the current object
+	if perObject, ok := rule.(PerObjectRule); ok && perObject.ForEachInput() != "" {
+		declsList = append(declsList, decls.NewVar(ObjectVariableName, decls.Dyn))
+	}
+
+	// Variables with an unsupported type are reported by ValidateVariables
+	for _, variable := range variables {
+		if decl, err := variableDecl(variable); err == nil {
+			declsList = append(declsList, decl)
+		}
+	}
+
 	return declsList
 }
 
@@ -199,9 +372,11 @@ func (v *RuleValidator) createValidationEnvironment(declarations []*expr.Decl) (
 
 	opts := []cel.EnvOption{
 		cel.StdLib(),
+		cel.OptionalTypes(),
 		jsonenvOpts,
 		yamlenvOpts,
 	}
+	opts = append(opts, v.envOptions...)
 
 	// Add variable declarations if provided
 	if len(declarations) > 0 {
@@ -211,11 +386,12 @@ func (v *RuleValidator) createValidationEnvironment(declarations []*expr.Decl) (
 	return cel.NewEnv(opts...)
 }
 
-// compileCELForValidation compiles a CEL expression and returns detailed validation issues
-func (v *RuleValidator) compileCELForValidation(env *cel.Env, expression string) []ValidationIssue {
+// compileCELForValidation compiles a CEL expression and returns the checked
+// expression along with detailed validation issues
+func (v *RuleValidator) compileCELForValidation(env *cel.Env, expression string) (*cel.Ast, []ValidationIssue) {
 	issues := []ValidationIssue{}
 
-	_, compileIssues := env.Compile(expression)
+	ast, compileIssues := env.Compile(expression)
 	if compileIssues.Err() != nil {
 		errMsg := compileIssues.Err().Error()
 
@@ -239,7 +415,7 @@ func (v *RuleValidator) compileCELForValidation(env *cel.Env, expression string)
 		}
 	}
 
-	return issues
+	return ast, issues
 }
 
 // categorizeCompilationError categorizes a compilation error and creates an issue
@@ -44,6 +44,110 @@ func (m *MockLogger) Error(msg string, args ...interface{}) {
 	m.Messages = append(m.Messages, "ERROR: "+msg)
 }
 
+func TestRuleValidator_WithCostLimit(t *testing.T) {
+	declsList := []*expr.Decl{decls.NewVar("pods", decls.Dyn)}
+
+	tests := []struct {
+		name        string
+		expression  string
+		costLimit   uint64
+		expectIssue bool
+	}{
+		{
+			name:       "single comprehension within budget",
+			expression: "pods.items.all(p, p.metadata.name != '')",
+			costLimit:  100000,
+		},
+		{
+			name:        "nested comprehension over budget",
+			expression:  "pods.items.all(a, pods.items.all(b, a.metadata.name != b.metadata.name))",
+			costLimit:   100000,
+			expectIssue: true,
+		},
+		{
+			name:       "no limit configured",
+			expression: "pods.items.all(a, pods.items.all(b, a.metadata.name != b.metadata.name))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewRuleValidator(&MockLogger{}).WithCostLimit(tt.costLimit)
+			issues := validator.ValidateCELExpressionWithInputs(tt.expression, declsList)
+
+			if !tt.expectIssue {
+				if len(issues) != 0 {
+					t.Errorf("Expected no issues, got %v", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].Type != ValidationErrorTypeCost {
+				t.Fatalf("Expected a single %s issue, got %v", ValidationErrorTypeCost, issues)
+			}
+			if !strings.Contains(issues[0].Message, "exceeds the cost limit of 100000") {
+				t.Errorf("Unexpected message: %s", issues[0].Message)
+			}
+		})
+	}
+}
+
+func TestValidateRule_Applicability(t *testing.T) {
+	tests := []struct {
+		name          string
+		applicability string
+		expectValid   bool
+		expectedType  ValidationErrorType
+	}{
+		{
+			name:          "boolean condition",
+			applicability: "pods.items.size() > 0",
+			expectValid:   true,
+		},
+		{
+			name:          "dynamic condition",
+			applicability: "pods.enabled",
+			expectValid:   true,
+		},
+		{
+			name:          "non-boolean condition",
+			applicability: "pods.items.size()",
+			expectedType:  ValidationErrorTypeType,
+		},
+		{
+			name:          "undeclared reference",
+			applicability: "nodes.items.size() > 0",
+			expectedType:  ValidationErrorTypeUndeclaredReference,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewRuleBuilder("applicability", RuleTypeCEL).
+				WithKubernetesInput("pods", "", "v1", "pods", "", "").
+				SetCelExpression("pods.items.all(p, p.metadata.name != '')").
+				WithApplicability(tt.applicability, "").
+				BuildCelRule()
+			if err != nil {
+				t.Fatalf("Failed to build rule: %v", err)
+			}
+
+			result := NewRuleValidator(&MockLogger{}).ValidateRule(rule)
+			if result.Valid != tt.expectValid {
+				t.Fatalf("Expected valid=%v, got %v (%v)", tt.expectValid, result.Valid, result.Issues)
+			}
+			if tt.expectValid {
+				return
+			}
+			if result.Issues[0].Type != tt.expectedType {
+				t.Errorf("Expected issue type %s, got %s", tt.expectedType, result.Issues[0].Type)
+			}
+			if !strings.HasPrefix(result.Issues[0].Message, "Applicability expression: ") {
+				t.Errorf("Expected the issue to name the applicability expression, got %q", result.Issues[0].Message)
+			}
+		})
+	}
+}
+
 // Test simple expression validation
 func TestValidateCELExpressionSimple(t *testing.T) {
 	tests := []struct {
@@ -530,3 +634,55 @@ func BenchmarkCompileCELExpression(b *testing.B) {
 		_ = CompileCELExpression(expression, inputs)
 	}
 }
+
+func TestValidateRule_MessageExpression(t *testing.T) {
+	tests := []struct {
+		name         string
+		message      string
+		expectValid  bool
+		expectedType ValidationErrorType
+	}{
+		{
+			name:        "string message",
+			message:     "string(pods.items.size()) + ' pods run as root'",
+			expectValid: true,
+		},
+		{
+			name:         "non-string message",
+			message:      "pods.items.size()",
+			expectedType: ValidationErrorTypeType,
+		},
+		{
+			name:         "undeclared reference",
+			message:      "nodes.items[0].metadata.name",
+			expectedType: ValidationErrorTypeUndeclaredReference,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewRuleBuilder("message", RuleTypeCEL).
+				WithKubernetesInput("pods", "", "v1", "pods", "", "").
+				SetCelExpression("pods.items.all(p, p.metadata.name != '')").
+				WithMessageExpression(tt.message).
+				BuildCelRule()
+			if err != nil {
+				t.Fatalf("Failed to build rule: %v", err)
+			}
+
+			result := NewRuleValidator(&MockLogger{}).ValidateRule(rule)
+			if result.Valid != tt.expectValid {
+				t.Fatalf("Expected valid=%v, got %v (%v)", tt.expectValid, result.Valid, result.Issues)
+			}
+			if tt.expectValid {
+				return
+			}
+			if result.Issues[0].Type != tt.expectedType {
+				t.Errorf("Expected issue type %s, got %s", tt.expectedType, result.Issues[0].Type)
+			}
+			if !strings.HasPrefix(result.Issues[0].Message, "Message expression: ") {
+				t.Errorf("Expected the issue to name the message expression, got %q", result.Issues[0].Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/checker/decls"
+	expr "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// variableType returns the declared type of a variable, string by default
+func variableType(variable CelVariable) VariableType {
+	if typed, ok := variable.(TypedCelVariable); ok && typed.Type() != "" {
+		return typed.Type()
+	}
+	return VariableTypeString
+}
+
+// variableDecl declares a variable with the CEL type matching its VariableType
+func variableDecl(variable CelVariable) (*expr.Decl, error) {
+	var declType *expr.Type
+	switch varType := variableType(variable); varType {
+	case VariableTypeString:
+		declType = decls.String
+	case VariableTypeInt:
+		declType = decls.Int
+	case VariableTypeDouble:
+		declType = decls.Double
+	case VariableTypeBool:
+		declType = decls.Bool
+	case VariableTypeDuration:
+		declType = decls.Duration
+	case VariableTypeList:
+		declType = decls.NewListType(decls.Dyn)
+	case VariableTypeMap:
+		declType = decls.NewMapType(decls.String, decls.Dyn)
+	default:
+		return nil, fmt.Errorf("variable %s has unsupported type %q", variable.Name(), varType)
+	}
+	return decls.NewVar(variable.Name(), declType), nil
+}
+
+// ParseVariableValue converts the string value of a variable into the Go
+// value bound in CEL for its declared type
+func ParseVariableValue(variable CelVariable) (interface{}, error) {
+	raw := variable.Value()
+	varType := variableType(variable)
+
+	var value interface{}
+	var err error
+	switch varType {
+	case VariableTypeString:
+		return raw, nil
+	case VariableTypeInt:
+		value, err = strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	case VariableTypeDouble:
+		value, err = strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	case VariableTypeBool:
+		value, err = strconv.ParseBool(strings.TrimSpace(raw))
+	case VariableTypeDuration:
+		value, err = time.ParseDuration(strings.TrimSpace(raw))
+	case VariableTypeList:
+		var list []interface{}
+		err = yaml.Unmarshal([]byte(raw), &list)
+		value = list
+	case VariableTypeMap:
+		var object map[string]interface{}
+		err = yaml.Unmarshal([]byte(raw), &object)
+		value = object
+	default:
+		return nil, fmt.Errorf("variable %s has unsupported type %q", variable.Name(), varType)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value %q for variable %s: %v", varType, raw, variable.Name(), err)
+	}
+	if value == nil || (varType == VariableTypeList && value.([]interface{}) == nil) || (varType == VariableTypeMap && value.(map[string]interface{}) == nil) {
+		return nil, fmt.Errorf("invalid %s value %q for variable %s: value is empty", varType, raw, variable.Name())
+	}
+	return value, nil
+}
+
+// resolveVariables reads the values of variables referencing Kubernetes
+// objects. Failures are returned as warnings keyed by variable name; a
+// variable that could not be resolved keeps its own value as a default, or is
+// left out when it has none.
+func (s *Scanner) resolveVariables(ctx context.Context, variables []CelVariable) ([]CelVariable, map[string]string) {
+	resolved := make([]CelVariable, 0, len(variables))
+	warnings := map[string]string{}
+
+	for _, variable := range variables {
+		ref, ok := variable.(ObjectReferenceVariable)
+		if !ok || ref.ObjectName() == "" {
+			resolved = append(resolved, variable)
+			continue
+		}
+
+		var value string
+		err := fmt.Errorf("no variable resolver is configured")
+		if s.variableResolver != nil {
+			value, err = s.variableResolver.ResolveVariable(ctx, ref)
+		}
+		if err == nil {
+			resolved = append(resolved, &CelVariableImpl{
+				VarName:      variable.Name(),
+				VarNamespace: variable.Namespace(),
+				VarValue:     value,
+				VarType:      variableType(variable),
+				GVK:          variable.GroupVersionKind(),
+			})
+			continue
+		}
+
+		warning := fmt.Sprintf("Failed to resolve variable %s from %s: %v", variable.Name(), objectReference(ref), err)
+		if variable.Value() != "" {
+			warning += fmt.Sprintf("; using default value %q", variable.Value())
+			resolved = append(resolved, variable)
+		}
+		s.logger.Warn("%s", warning)
+		warnings[variable.Name()] = warning
+	}
+
+	return resolved, warnings
+}
+
+// objectReference describes the object field a variable references
+func objectReference(ref ObjectReferenceVariable) string {
+	name := ref.ObjectName()
+	if ref.Namespace() != "" {
+		name = ref.Namespace() + "/" + name
+	}
+	return fmt.Sprintf("%s %s field %s", ref.GroupVersionKind().Kind, name, ref.FieldPath())
+}
+
+// variableWarningsFor returns the resolution warnings of the variables a rule
+// mentions in its expressions
+func (r *scanRun) variableWarningsFor(rule Rule) []string {
+	if len(r.variableWarnings) == 0 {
+		return nil
+	}
+
+	var warnings []string
+	names := make([]string, 0, len(r.variableWarnings))
+	for name := range r.variableWarnings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if RuleMentionsVariable(rule, name) {
+			warnings = append(warnings, r.variableWarnings[name])
+		}
+	}
+	return warnings
+}
+
+// RuleMentionsVariable reports whether the expression, applicability
+// expression or message expression of a rule mentions the variable name
+func RuleMentionsVariable(rule Rule, name string) bool {
+	var expressions []string
+	if celRule, ok := rule.(CelRule); ok {
+		expressions = append(expressions, celRule.Expression())
+	}
+	if applicable, ok := rule.(ApplicableRule); ok {
+		expressions = append(expressions, applicable.ApplicabilityExpression())
+	}
+	expressions = append(expressions, messageExpression(rule))
+
+	for _, expression := range expressions {
+		if mentionsIdentifier(expression, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionsIdentifier reports whether name appears in expression as a whole identifier
+func mentionsIdentifier(expression, name string) bool {
+	isIdentChar := func(b byte) bool {
+		return b == '_' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+	}
+	for offset := 0; ; {
+		i := strings.Index(expression[offset:], name)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(name)
+		if (start == 0 || !isIdentChar(expression[start-1])) && (end == len(expression) || !isIdentChar(expression[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
@@ -0,0 +1,291 @@
+/*
+Copyright © 2025 Red Hat Inc.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestParseVariableValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		variable    CelVariable
+		expected    interface{}
+		expectError bool
+	}{
+		{
+			name:     "untyped variable is a string",
+			variable: &TestCelVariable{name: "v", value: "42"},
+			expected: "42",
+		},
+		{
+			name:     "int",
+			variable: NewCelVariable("v", " 42 ", VariableTypeInt),
+			expected: int64(42),
+		},
+		{
+			name:     "double",
+			variable: NewCelVariable("v", "0.5", VariableTypeDouble),
+			expected: 0.5,
+		},
+		{
+			name:     "bool",
+			variable: NewCelVariable("v", "true", VariableTypeBool),
+			expected: true,
+		},
+		{
+			name:     "duration",
+			variable: NewCelVariable("v", "1h30m", VariableTypeDuration),
+			expected: 90 * time.Minute,
+		},
+		{
+			name:     "list",
+			variable: NewCelVariable("v", `["a", "b"]`, VariableTypeList),
+			expected: []interface{}{"a", "b"},
+		},
+		{
+			name:     "yaml map",
+			variable: NewCelVariable("v", "limit: 3", VariableTypeMap),
+			expected: map[string]interface{}{"limit": float64(3)},
+		},
+		{
+			name:        "invalid int",
+			variable:    NewCelVariable("v", "ten", VariableTypeInt),
+			expectError: true,
+		},
+		{
+			name:        "empty list",
+			variable:    NewCelVariable("v", "", VariableTypeList),
+			expectError: true,
+		},
+		{
+			name:        "unsupported type",
+			variable:    NewCelVariable("v", "x", VariableType("timestamp")),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := ParseVariableValue(tt.variable)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Expected an error, got %v", value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(value, tt.expected) {
+				t.Errorf("Expected %#v, got %#v", tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestScanner_TypedVariables(t *testing.T) {
+	tests := []struct {
+		name           string
+		expression     string
+		variables      []CelVariable
+		expectedStatus CheckResultStatus
+	}{
+		{
+			name:       "int compared numerically",
+			expression: "pods.items.size() <= maxPods",
+			variables: []CelVariable{
+				NewCelVariable("maxPods", "10", VariableTypeInt),
+			},
+			expectedStatus: CheckResultPass,
+		},
+		{
+			name:       "duration and list",
+			expression: "timeout > duration('30s') && 'pod-0' in allowed",
+			variables: []CelVariable{
+				NewCelVariable("timeout", "1m", VariableTypeDuration),
+				NewCelVariable("allowed", "[pod-0, pod-1]", VariableTypeList),
+			},
+			expectedStatus: CheckResultPass,
+		},
+		{
+			name:       "map",
+			expression: "pods.items.size() < limits.pods",
+			variables: []CelVariable{
+				NewCelVariable("limits", `{"pods": 1}`, VariableTypeMap),
+			},
+			expectedStatus: CheckResultFail,
+		},
+		{
+			name:       "invalid value",
+			expression: "pods.items.size() <= maxPods",
+			variables: []CelVariable{
+				NewCelVariable("maxPods", "many", VariableTypeInt),
+			},
+			expectedStatus: CheckResultError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewRuleBuilder("typed-variables", RuleTypeCEL).
+				WithKubernetesInput("pods", "", "v1", "pods", "", "").
+				SetCelExpression(tt.expression).
+				BuildCelRule()
+			if err != nil {
+				t.Fatalf("Failed to build rule: %v", err)
+			}
+
+			fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(2)}}
+			scanner := NewScanner(fetcher, &TestLogger{t: t})
+			results, err := scanner.Scan(context.Background(), ScanConfig{
+				Rules:     []Rule{rule},
+				Variables: tt.variables,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if results[0].Status != tt.expectedStatus {
+				t.Errorf("Expected %s, got %s (%s)", tt.expectedStatus, results[0].Status, results[0].ErrorMessage)
+			}
+		})
+	}
+}
+
+func TestRuleValidator_ValidateRuleWithVariables(t *testing.T) {
+	tests := []struct {
+		name         string
+		expression   string
+		variable     CelVariable
+		expectValid  bool
+		expectedType ValidationErrorType
+	}{
+		{
+			name:        "typed variable",
+			expression:  "pods.items.size() <= maxPods",
+			variable:    NewCelVariable("maxPods", "10", VariableTypeInt),
+			expectValid: true,
+		},
+		{
+			name:       "string variable compared to an int",
+			expression: "pods.items.size() <= maxPods",
+			variable:   &TestCelVariable{name: "maxPods", value: "10"},
+		},
+		{
+			name:         "bad value",
+			expression:   "pods.items.size() <= maxPods",
+			variable:     NewCelVariable("maxPods", "ten", VariableTypeInt),
+			expectedType: ValidationErrorTypeVariable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewRuleBuilder("typed-variables", RuleTypeCEL).
+				WithKubernetesInput("pods", "", "v1", "pods", "", "").
+				SetCelExpression(tt.expression).
+				BuildCelRule()
+			if err != nil {
+				t.Fatalf("Failed to build rule: %v", err)
+			}
+
+			result := NewRuleValidator(&MockLogger{}).ValidateRuleWithVariables(rule, []CelVariable{tt.variable})
+			if result.Valid != tt.expectValid {
+				t.Fatalf("Expected valid=%v, got %v (%v)", tt.expectValid, result.Valid, result.Issues)
+			}
+			if tt.expectedType != "" && result.Issues[0].Type != tt.expectedType {
+				t.Errorf("Expected issue type %s, got %s (%s)", tt.expectedType, result.Issues[0].Type, result.Issues[0].Message)
+			}
+		})
+	}
+}
+
+// stubResolver resolves variables from a map keyed by object name
+type stubResolver map[string]string
+
+func (r stubResolver) ResolveVariable(ctx context.Context, variable ObjectReferenceVariable) (string, error) {
+	value, ok := r[variable.ObjectName()]
+	if !ok {
+		return "", fmt.Errorf("configmaps %q not found", variable.ObjectName())
+	}
+	return value, nil
+}
+
+func TestScanner_ResolveVariables(t *testing.T) {
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	withDefault := NewObjectCelVariable("minPods", gvk, "compliance", "missing", "data.minPods", VariableTypeInt).(*CelVariableImpl)
+	withDefault.VarValue = "1"
+
+	variables := []CelVariable{
+		NewObjectCelVariable("maxPods", gvk, "compliance", "tailoring", "data.maxPods", VariableTypeInt),
+		withDefault,
+		NewObjectCelVariable("labels", gvk, "compliance", "missing", "data.labels", VariableTypeList),
+	}
+
+	rules := []Rule{
+		NewCelRule("max-pods", "pods.items.size() <= maxPods", []Input{NewKubernetesInput("pods", "", "v1", "pods", "", "")}),
+		NewCelRule("min-pods", "pods.items.size() >= minPods", []Input{NewKubernetesInput("pods", "", "v1", "pods", "", "")}),
+		NewCelRule("labels", "labels.size() > 0", []Input{NewKubernetesInput("pods", "", "v1", "pods", "", "")}),
+	}
+	messageRule, err := NewRuleBuilder("pod-count", RuleTypeCEL).
+		WithKubernetesInput("pods", "", "v1", "pods", "", "").
+		SetCelExpression("pods.items.size() > 5").
+		WithMessageExpression("'expected more than ' + string(minPods) + ' pods'").
+		BuildCelRule()
+	if err != nil {
+		t.Fatalf("Failed to build rule: %v", err)
+	}
+	rules = append(rules, messageRule)
+
+	fetcher := &countingFetcher{data: map[string]interface{}{"pods": podList(2)}}
+	scanner := NewScanner(fetcher, &TestLogger{t: t}).WithVariableResolver(stubResolver{"tailoring": "5"})
+	results, err := scanner.Scan(context.Background(), ScanConfig{Rules: rules, Variables: variables})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].Status != CheckResultPass || len(results[0].Warnings) != 0 {
+		t.Errorf("Expected resolved variable to pass without warnings, got %s %v (%s)", results[0].Status, results[0].Warnings, results[0].ErrorMessage)
+	}
+
+	if results[1].Status != CheckResultPass {
+		t.Errorf("Expected default value to be used, got %s (%s)", results[1].Status, results[1].ErrorMessage)
+	}
+	if len(results[1].Warnings) != 1 || !strings.Contains(results[1].Warnings[0], `using default value "1"`) {
+		t.Errorf("Expected a resolution warning naming the default, got %v", results[1].Warnings)
+	}
+
+	if results[2].Status != CheckResultError {
+		t.Errorf("Expected unresolved variable without default to be an error, got %s", results[2].Status)
+	}
+	if len(results[2].Warnings) == 0 || !strings.Contains(results[2].Warnings[0], "ConfigMap compliance/missing field data.labels") {
+		t.Errorf("Expected a resolution warning naming the object, got %v", results[2].Warnings)
+	}
+
+	if results[3].Status != CheckResultFail || results[3].ErrorMessage != "expected more than 1 pods" {
+		t.Errorf("Expected the message to use the default value, got %s (%s)", results[3].Status, results[3].ErrorMessage)
+	}
+	if len(results[3].Warnings) != 1 || !strings.Contains(results[3].Warnings[0], `using default value "1"`) {
+		t.Errorf("Expected the resolution warning of a variable used only in the message, got %v", results[3].Warnings)
+	}
+}